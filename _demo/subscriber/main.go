@@ -4,7 +4,6 @@ package main
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/OI4/oi4-oec-service-go/service/api"
 	"github.com/OI4/oi4-oec-service-go/service/application"
 	"github.com/OI4/oi4-oec-service-go/service/application/source"
@@ -71,8 +70,8 @@ func main() {
 		panic(err)
 	}
 
-	subscriptionTopic := fmt.Sprintf("%s/+/+/+/+/+/%s/%s/#", tp.Oi4Namespace, api.MethodPub, api.ResourceHealth)
-	dataApplicationSubscription := subscription.NewTopicSubscription(subscriptionTopic, handler(oi4Application))
+	subscriptionFilter := tp.NewFilter().Method(api.MethodPub).Resource(api.ResourceHealth)
+	dataApplicationSubscription := subscription.NewTopicSubscription(subscriptionFilter, handler(oi4Application))
 	err = oi4Application.RegisterSubscription(dataApplicationSubscription)
 	if err != nil {
 		logger.Fatal("Failed to register publication:", err)
@@ -0,0 +1,9 @@
+package api
+
+// Codec encodes and decodes the payload of MQTT messages, e.g. NetworkMessages
+type Codec interface {
+	// ContentType is the MIME type of the encoded payload
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
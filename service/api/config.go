@@ -1,7 +1,299 @@
 package api
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+)
+
+// ConfigParameterType is the type of the value of a config parameter
+type ConfigParameterType string
+
+const (
+	ConfigParameterString  ConfigParameterType = "String"
+	ConfigParameterNumber  ConfigParameterType = "Number"
+	ConfigParameterBoolean ConfigParameterType = "Boolean"
+)
+
+const (
+	configContext     = "Context"
+	configName        = "Name"
+	configDescription = "Description"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+// PublishConfig is the configuration of a source. In the payload the groups are properties next to the Context, and
+// the parameters of a group are properties next to its Name and Description:
+//
+//	{"Context": {...}, "<Group>": {"Name": {...}, "<Parameter>": {"Name": {...}, "Type": "Number", "Value": 1}}}
 type PublishConfig struct {
+	Context *ConfigContext
+	Groups  map[string]ConfigGroup
+}
+
+type ConfigContext struct {
+	Name        LocalizedText  `json:"Name"`
+	Description *LocalizedText `json:"Description,omitempty"`
+}
+
+type ConfigGroup struct {
+	Name        LocalizedText
+	Description *LocalizedText
+	Parameters  map[string]ConfigParameter
+}
+
+type ConfigParameter struct {
+	Name        LocalizedText       `json:"Name"`
+	Description *LocalizedText      `json:"Description,omitempty"`
+	Type        ConfigParameterType `json:"Type"`
+	Value       any                 `json:"Value"`
+	Unit        *string             `json:"Unit,omitempty"`
+	Validation  *ConfigValidation   `json:"Validation,omitempty"`
+}
+
+// ConfigValidation restricts the values of a parameter. Length and Pattern apply to strings, Min and Max to numbers.
+type ConfigValidation struct {
+	Length  *uint32  `json:"Length,omitempty"`
+	Min     *float64 `json:"Min,omitempty"`
+	Max     *float64 `json:"Max,omitempty"`
+	Pattern *string  `json:"Pattern,omitempty"`
+	Values  []any    `json:"Values,omitempty"`
+}
+
+// SetConfig contains the new values of a Set Config request by group and parameter name. The payload has the
+// structure of the PublishConfig, only the values of the parameters are used.
+type SetConfig map[string]map[string]any
+
+func (config PublishConfig) MarshalJSON() ([]byte, error) {
+	result := make(map[string]any, len(config.Groups)+1)
+	for name, group := range config.Groups {
+		result[name] = group
+	}
+	if config.Context != nil {
+		result[configContext] = config.Context
+	}
+	return json.Marshal(result)
+}
+
+func (config *PublishConfig) UnmarshalJSON(data []byte) error {
+	properties := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return err
+	}
+
+	config.Context = nil
+	config.Groups = make(map[string]ConfigGroup, len(properties))
+	for name, property := range properties {
+		if name == configContext {
+			config.Context = &ConfigContext{}
+			if err := json.Unmarshal(property, config.Context); err != nil {
+				return err
+			}
+			continue
+		}
+
+		group := ConfigGroup{}
+		if err := json.Unmarshal(property, &group); err != nil {
+			return fmt.Errorf("group %s: %w", name, err)
+		}
+		config.Groups[name] = group
+	}
+	return nil
+}
+
+func (group ConfigGroup) MarshalJSON() ([]byte, error) {
+	result := make(map[string]any, len(group.Parameters)+2)
+	for name, parameter := range group.Parameters {
+		result[name] = parameter
+	}
+	result[configName] = group.Name
+	if group.Description != nil {
+		result[configDescription] = group.Description
+	}
+	return json.Marshal(result)
+}
+
+func (group *ConfigGroup) UnmarshalJSON(data []byte) error {
+	properties := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return err
+	}
+
+	*group = ConfigGroup{Parameters: make(map[string]ConfigParameter, len(properties))}
+	for name, property := range properties {
+		var err error
+		switch name {
+		case configName:
+			err = json.Unmarshal(property, &group.Name)
+		case configDescription:
+			group.Description = &LocalizedText{}
+			err = json.Unmarshal(property, group.Description)
+		default:
+			parameter := ConfigParameter{}
+			err = json.Unmarshal(property, &parameter)
+			group.Parameters[name] = parameter
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (config SetConfig) MarshalJSON() ([]byte, error) {
+	result := make(map[string]map[string]any, len(config))
+	for groupName, values := range config {
+		group := make(map[string]any, len(values))
+		for name, value := range values {
+			group[name] = map[string]any{"Value": value}
+		}
+		result[groupName] = group
+	}
+	return json.Marshal(result)
+}
+
+func (config *SetConfig) UnmarshalJSON(data []byte) error {
+	properties := make(map[string]map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return err
+	}
+
+	*config = make(SetConfig, len(properties))
+	for groupName, group := range properties {
+		if groupName == configContext {
+			continue
+		}
+
+		values := make(map[string]any, len(group))
+		for name, property := range group {
+			if name == configName || name == configDescription {
+				continue
+			}
+
+			parameter := struct {
+				Value *any `json:"Value"`
+			}{}
+			if err := json.Unmarshal(property, &parameter); err != nil {
+				return fmt.Errorf("%s/%s: %w", groupName, name, err)
+			}
+			if parameter.Value != nil {
+				values[name] = *parameter.Value
+			}
+		}
+		(*config)[groupName] = values
+	}
+	return nil
+}
+
+// Values returns the values of all parameters, e.g. to restore a persisted config
+func (config PublishConfig) Values() SetConfig {
+	result := make(SetConfig, len(config.Groups))
+	for groupName, group := range config.Groups {
+		values := make(map[string]any, len(group.Parameters))
+		for name, parameter := range group.Parameters {
+			values[name] = parameter.Value
+		}
+		result[groupName] = values
+	}
+	return result
+}
+
+// Apply validates the values against the parameters of the config and returns a copy of the config with the new
+// values. The config is unchanged, if a value is invalid or refers to an unknown group or parameter.
+func (config PublishConfig) Apply(values SetConfig) (PublishConfig, error) {
+	result := PublishConfig{Context: config.Context, Groups: maps.Clone(config.Groups)}
+	for groupName, groupValues := range values {
+		group, ok := result.Groups[groupName]
+		if !ok {
+			return config, fmt.Errorf("%w: unknown group %s", ErrInvalidConfig, groupName)
+		}
+
+		group.Parameters = maps.Clone(group.Parameters)
+		for name, value := range groupValues {
+			parameter, ok := group.Parameters[name]
+			if !ok {
+				return config, fmt.Errorf("%w: unknown parameter %s/%s", ErrInvalidConfig, groupName, name)
+			}
+			if err := parameter.validate(value); err != nil {
+				return config, fmt.Errorf("%w: %s/%s %v", ErrInvalidConfig, groupName, name, err)
+			}
+			parameter.Value = value
+			group.Parameters[name] = parameter
+		}
+		result.Groups[groupName] = group
+	}
+	return result, nil
+}
+
+func (parameter ConfigParameter) validate(value any) error {
+	validation := parameter.Validation
+	if validation == nil {
+		validation = &ConfigValidation{}
+	}
+
+	switch parameter.Type {
+	case ConfigParameterString:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("must be a string")
+		}
+		if validation.Length != nil && uint32(len([]rune(text))) > *validation.Length {
+			return fmt.Errorf("exceeds the length of %d", *validation.Length)
+		}
+		if validation.Pattern != nil {
+			if matched, err := regexp.MatchString(*validation.Pattern, text); err != nil || !matched {
+				return fmt.Errorf("does not match %s", *validation.Pattern)
+			}
+		}
+	case ConfigParameterNumber:
+		number, ok := toFloat(value)
+		if !ok {
+			return fmt.Errorf("must be a number")
+		}
+		if validation.Min != nil && number < *validation.Min {
+			return fmt.Errorf("is less than %v", *validation.Min)
+		}
+		if validation.Max != nil && number > *validation.Max {
+			return fmt.Errorf("is greater than %v", *validation.Max)
+		}
+	case ConfigParameterBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("must be a boolean")
+		}
+	}
+
+	if len(validation.Values) > 0 && !slices.ContainsFunc(validation.Values, func(allowed any) bool { return equalValue(allowed, value) }) {
+		return fmt.Errorf("is not one of %v", validation.Values)
+	}
+	return nil
+}
+
+func toFloat(value any) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case float32:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case uint64:
+		return float64(number), true
+	default:
+		return 0, false
+	}
 }
 
-type SetConfig struct {
+// equalValue compares numbers independent of their type, as decoded payloads contain float64 only
+func equalValue(a any, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return a == b
 }
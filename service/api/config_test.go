@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testConfig() PublishConfig {
+	maxInterval := 60.0
+	length := uint32(8)
+	return PublishConfig{
+		Context: &ConfigContext{Name: LocalizedText{Locale: "en-US", Text: "Connector"}},
+		Groups: map[string]ConfigGroup{
+			"Polling": {
+				Name: LocalizedText{Locale: "en-US", Text: "Polling"},
+				Parameters: map[string]ConfigParameter{
+					"Interval": {Name: LocalizedText{Locale: "en-US", Text: "Interval"}, Type: ConfigParameterNumber, Value: 10.0, Validation: &ConfigValidation{Max: &maxInterval}},
+					"Mode":     {Name: LocalizedText{Locale: "en-US", Text: "Mode"}, Type: ConfigParameterString, Value: "fast", Validation: &ConfigValidation{Length: &length, Values: []any{"fast", "slow"}}},
+					"Enabled":  {Name: LocalizedText{Locale: "en-US", Text: "Enabled"}, Type: ConfigParameterBoolean, Value: true},
+				},
+			},
+		},
+	}
+}
+
+func TestPublishConfigJsonLayout(t *testing.T) {
+	data, err := json.Marshal(testConfig())
+	require.NoError(t, err)
+
+	layout := make(map[string]map[string]any)
+	require.NoError(t, json.Unmarshal(data, &layout))
+	assert.Contains(t, layout, "Context")
+	assert.Contains(t, layout["Polling"], "Name")
+	assert.Contains(t, layout["Polling"], "Interval")
+
+	decoded := PublishConfig{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, testConfig(), decoded)
+}
+
+func TestSetConfigUsesValuesOnly(t *testing.T) {
+	payload := `{"Context": {"Name": {"Locale": "en-US", "Text": "Connector"}},
+		"Polling": {"Name": {"Locale": "en-US", "Text": "Polling"}, "Interval": {"Type": "Number", "Value": 30}, "Mode": {"Name": {}}}}`
+
+	values := SetConfig{}
+	require.NoError(t, json.Unmarshal([]byte(payload), &values))
+	assert.Equal(t, SetConfig{"Polling": {"Interval": 30.0}}, values)
+
+	data, err := json.Marshal(values)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Polling": {"Interval": {"Value": 30}}}`, string(data))
+}
+
+func TestApplyConfig(t *testing.T) {
+	config := testConfig()
+
+	applied, err := config.Apply(SetConfig{"Polling": {"Interval": 30.0, "Mode": "slow", "Enabled": false}})
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, applied.Groups["Polling"].Parameters["Interval"].Value)
+	assert.Equal(t, "slow", applied.Groups["Polling"].Parameters["Mode"].Value)
+	assert.Equal(t, false, applied.Groups["Polling"].Parameters["Enabled"].Value)
+	// the config itself is unchanged
+	assert.Equal(t, 10.0, config.Groups["Polling"].Parameters["Interval"].Value)
+
+	invalid := []SetConfig{
+		{"Unknown": {"Interval": 1.0}},
+		{"Polling": {"Unknown": 1.0}},
+		{"Polling": {"Interval": "30"}},
+		{"Polling": {"Interval": 61.0}},
+		{"Polling": {"Mode": "medium"}},
+		{"Polling": {"Mode": "very fast mode"}},
+		{"Polling": {"Enabled": 1.0}},
+	}
+	for _, values := range invalid {
+		_, err = config.Apply(values)
+		assert.ErrorIs(t, err, ErrInvalidConfig, "%v", values)
+	}
+}
+
+func TestConfigValues(t *testing.T) {
+	assert.Equal(t, SetConfig{"Polling": {"Interval": 10.0, "Mode": "fast", "Enabled": true}}, testConfig().Values())
+}
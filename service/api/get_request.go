@@ -0,0 +1,23 @@
+package api
+
+// DataSetWriterIds reserved by the guideline for special DataSetMessages
+const (
+	DataSetWriterIdPaginationRequest uint16 = 1
+	DataSetWriterIdPagination        uint16 = 2
+	DataSetWriterIdLocale            uint16 = 3
+)
+
+// GetRequest is a received Get request, combining the levels of the topic with the DataSetMessages of the payload
+type GetRequest struct {
+	// MessageId of the request, the responses refer to it as CorrelationId
+	MessageId string
+	Resource  ResourceType
+	// Source is nil if the resource of all sources is requested
+	Source *Oi4Identifier
+	// Filter of the topic, or else of the first DataSetMessage providing one
+	Filter *Filter
+	// Pagination is the requested page, nil if the complete resource is requested
+	Pagination *PaginationRequest
+	// Locale is the requested language, nil if the default language is requested
+	Locale *Locale
+}
@@ -0,0 +1,36 @@
+package api
+
+import "reflect"
+
+// Method is a method of a service, which is called with a Call request and answered with a Reply
+type Method struct {
+	// InputArguments are validated before the handler is called, their number must match and every argument must be
+	// decodable into the type of the argument
+	InputArguments []MethodArgument
+	// Handler is called with the decoded input arguments of the types of the InputArguments. A missing optional
+	// argument is nil. The status code is the status of the method result, the output arguments are sent with any
+	// status, e.g. a rejected file upload returns the offset to resume from.
+	Handler func(source BaseSource, inputArguments []any) ([]any, StatusCode)
+}
+
+// MethodRegistry is implemented by the application and its assets, the services register their methods with it
+type MethodRegistry interface {
+	RegisterMethod(service ResourceType, methodId string, method Method) error
+}
+
+type MethodArgument struct {
+	Name string
+	Type reflect.Type
+	// Optional arguments may be omitted at the end of the input arguments
+	Optional bool
+}
+
+// NewArgument creates a mandatory input argument, the value is decoded into T
+func NewArgument[T any](name string) MethodArgument {
+	return MethodArgument{Name: name, Type: reflect.TypeFor[T]()}
+}
+
+// NewOptionalArgument creates an input argument which may be omitted, the value is decoded into T
+func NewOptionalArgument[T any](name string) MethodArgument {
+	return MethodArgument{Name: name, Type: reflect.TypeFor[T](), Optional: true}
+}
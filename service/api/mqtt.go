@@ -1,11 +1,45 @@
 package api
 
-import mqtt "github.com/eclipse/paho.mqtt.golang"
+import (
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"time"
+)
+
+// DropPolicy defines which message is discarded when the offline buffer is full
+type DropPolicy int
+
+const (
+	// DropOldest discards the oldest buffered message to make room for the new one
+	DropOldest DropPolicy = iota
+	// DropNewest discards the message that should be buffered
+	DropNewest
+)
+
+// Transport is the protocol the MQTT connection is carried over, secured with TLS if enabled in the options
+type Transport string
+
+const (
+	// TransportTcp plain MQTT, the default
+	TransportTcp Transport = "tcp"
+	// TransportWebSocket MQTT over WebSockets, e.g. for brokers only reachable through a reverse proxy
+	TransportWebSocket Transport = "ws"
+)
+
+// BrokerEndpoint is an alternative broker used for failover
+type BrokerEndpoint struct {
+	Host      string
+	Port      int
+	Transport Transport
+	// Path of the WebSocket endpoint, e.g. /mqtt
+	Path string
+}
 
 type MqttClientOptions struct {
 	Host                          string
 	Tls                           bool
 	Port                          int
+	Transport                     Transport
+	Path                          string
 	Username                      string
 	Password                      string
 	Client_private_key_pem        string
@@ -14,15 +48,78 @@ type MqttClientOptions struct {
 	Ca_certificate_pem            string
 	TlsVerify                     bool
 	ClientId                      string
+
+	// Brokers tried in the given order if the broker defined by Host and Port is not reachable
+	FailoverEndpoints []BrokerEndpoint
+
+	// Upper bound of the exponential reconnect backoff, which starts at 1 second. Defaults to 2 minutes.
+	MaxReconnectInterval time.Duration
+	// Maximum number of publications buffered while the client is offline. Defaults to 1000, a negative value disables buffering.
+	OfflineBufferSize int
+	// Policy applied when the offline buffer is full.
+	OfflineDropPolicy DropPolicy
+
+	// Provides the last will, which the broker publishes if the client disconnects ungracefully.
+	// It is called before every connection attempt, so the will carries the timestamp of the latest connect.
+	WillFn func() (*MqttWill, error)
+	// Notified about every connection state change, including the initial connect
+	ConnectionListener ConnectionListener
+
+	// Encodes the published payloads and the last will. Defaults to JSON.
+	Codec Codec
+}
+
+// ConnectionState is a state in the connection lifecycle of a MqttClient
+type ConnectionState int
+
+const (
+	// Connected the initial connection to the broker is established
+	Connected ConnectionState = iota
+	// ConnectionLost the connection to the broker is lost unexpectedly
+	ConnectionLost
+	// Reconnecting the client attempts to restore the connection
+	Reconnecting
+	// Reconnected the connection is restored, subscriptions and buffered publications are restored as well
+	Reconnected
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case Connected:
+		return "Connected"
+	case ConnectionLost:
+		return "ConnectionLost"
+	case Reconnecting:
+		return "Reconnecting"
+	case Reconnected:
+		return "Reconnected"
+	default:
+		return "Unknown"
+	}
+}
+
+// ConnectionListener is notified about connection state changes, err is the cause of a ConnectionLost
+type ConnectionListener func(state ConnectionState, err error)
+
+type MqttWill struct {
+	Topic    string
+	Qos      byte
+	Retained bool
+	Payload  interface{}
 }
 
 type MessageHandler interface {
 	GetHandler() mqtt.MessageHandler
 }
 type MqttClient interface {
-	PublishResource(topic string, qos byte, data interface{}) error
+	PublishResource(topic string, qos byte, retained bool, data interface{}, opts ...PublishOption) error
+	ClearRetained(topic string) error
 	RegisterGetHandler(serviceType ServiceType, appId Oi4Identifier, qos byte, handler MessageHandler) error
 	Subscribe(subscription Subscription) error
 	SubscribeToTopic(topic string, qos byte, handler MessageHandler) error
+	// Unsubscribe removes the subscription of a topic filter, it is not restored after a reconnect
+	Unsubscribe(topic string) error
+	IsConnected() bool
+	AddConnectionListener(listener ConnectionListener)
 	Stop()
 }
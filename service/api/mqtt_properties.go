@@ -0,0 +1,66 @@
+package api
+
+import (
+	"time"
+)
+
+// MessageProperties are the MQTT 5 properties of a message. Clients using MQTT 3.1.1 do not transmit them.
+type MessageProperties struct {
+	ContentType     string
+	CorrelationData []byte
+	ResponseTopic   string
+	// MessageExpiry is the lifetime of the message in seconds, nil if it does not expire
+	MessageExpiry  *uint32
+	UserProperties map[string]string
+}
+
+// PropertiesMessage is implemented by the received messages of clients supporting MQTT 5
+type PropertiesMessage interface {
+	Properties() *MessageProperties
+}
+
+// PublishOption sets a MQTT 5 property of a publication
+type PublishOption func(properties *MessageProperties)
+
+// NewMessageProperties returns the properties defined by the options or nil if there are none
+func NewMessageProperties(opts ...PublishOption) *MessageProperties {
+	if len(opts) == 0 {
+		return nil
+	}
+
+	properties := &MessageProperties{}
+	for _, opt := range opts {
+		opt(properties)
+	}
+	return properties
+}
+
+// WithMessageExpiry lets the broker discard the message if it cannot be delivered within the expiry.
+// The expiry is rounded up to full seconds.
+func WithMessageExpiry(expiry time.Duration) PublishOption {
+	return func(properties *MessageProperties) {
+		seconds := uint32((expiry + time.Second - 1) / time.Second)
+		properties.MessageExpiry = &seconds
+	}
+}
+
+func WithUserProperty(key string, value string) PublishOption {
+	return func(properties *MessageProperties) {
+		if properties.UserProperties == nil {
+			properties.UserProperties = make(map[string]string)
+		}
+		properties.UserProperties[key] = value
+	}
+}
+
+func WithResponseTopic(topic string) PublishOption {
+	return func(properties *MessageProperties) {
+		properties.ResponseTopic = topic
+	}
+}
+
+func WithCorrelationData(data []byte) PublishOption {
+	return func(properties *MessageProperties) {
+		properties.CorrelationData = data
+	}
+}
@@ -13,6 +13,12 @@ type Oi4Application interface {
 	SendPublicationMessage(publication PublicationMessage)
 	SendGetMessage(topic string, getMessage GetMessage) error
 	GetIntervalPublicationScheduler() IntervalPublicationScheduler
+	GetPaginator() Paginator
+	AddConnectionListener(listener ConnectionListener)
+	IsConnected() bool
+	// GetDataSetWriterId returns the DataSetWriterId of the publication of the resource, source and filter
+	GetDataSetWriterId(resource ResourceType, source *Oi4Identifier, filter *Filter) uint16
 
 	PublicationProvider
+	SubscriptionProvider
 }
@@ -3,6 +3,8 @@ package api
 type PaginationRequest struct {
 	PerPage uint32 `json:"PerPage"`
 	Page    uint32 `json:"Page"`
+	// PaginationId of a previous response, the following pages are served from the same snapshot of the resource
+	PaginationId string `json:"PaginationId,omitempty"`
 }
 
 type PaginationResponse struct {
@@ -12,3 +14,19 @@ type PaginationResponse struct {
 	HasNext      bool   `json:"HasNext"`
 	PaginationId string `json:"PaginationId"`
 }
+
+// Page is a part of a paginated resource, Pagination is nil if the resource fits into a single page
+type Page struct {
+	Content    []any
+	Pagination *PaginationResponse
+	Locale     *Locale
+}
+
+// Paginator splits large resources into pages
+type Paginator interface {
+	// Paginate returns the requested page of a Get request with pagination, otherwise all pages of the resource.
+	// The content is provided by get, unless the page is served from the snapshot of a previous request.
+	// The snapshots are kept per publication, which is identified by its resource, source and filter, a PaginationId
+	// of another publication returns no page.
+	Paginate(publication string, request *GetRequest, get func() ([]any, *Locale)) []Page
+}
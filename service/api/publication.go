@@ -31,11 +31,14 @@ type Publication interface {
 	GetID() string
 	GetDataSetWriterId() uint16
 	GetPublicationMode() *PublicationMode
+	IsRetained() bool
 
 	Stop()
 	Start()
 
 	TriggerPublication(trigger Trigger, correlationId *string) bool
+	// TriggerRequestedPublication responds to a Get request with the filter, page and locale of the request
+	TriggerRequestedPublication(request GetRequest) bool
 
 	//triggerSourcePublication(byInterval bool, onRequest bool, correlationId string)
 	publishOnRegistration() bool
@@ -55,7 +58,20 @@ type PublicationMessage struct {
 	//Data       any
 	//StatusCode StatusCode
 	*Filter
-	Content []PublicationContent
+	// Category is the topic level of events between the source and the filter
+	Category *string
+	Content  []PublicationContent
+	// Retained messages are kept by the broker and delivered to every new subscriber of the topic. If the content
+	// is split into several NetworkMessages to fit the max packet size, only the last one is retained.
+	Retained bool
+	// MessageExpiry of the publication with MQTT 5, 0 if it does not expire
+	MessageExpiry time.Duration
+	// Pagination is added as DataSetMessage if the content is a page of the resource
+	Pagination *PaginationResponse
+	// Locale is added as DataSetMessage if the content is localized
+	Locale *Locale
+	// DataSetWriterId of the DataSetMessages, 0 if the application assigns the id of the resource, source and filter
+	DataSetWriterId uint16
 }
 
 type PublicationContent struct {
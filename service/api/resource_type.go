@@ -19,6 +19,18 @@ const (
 	ResourceSubscriptionList     ResourceType = "SubscriptionList"
 	ResourceInterfaces           ResourceType = "Interfaces"
 	ResourceReferenceDesignation ResourceType = "ReferenceDesignation"
+	ResourceFileUpload           ResourceType = "FileUpload"
+	ResourceFileDownload         ResourceType = "FileDownload"
+	ResourceFirmwareUpdate       ResourceType = "FirmwareUpdate"
+	ResourceBlink                ResourceType = "Blink"
+	ResourceNewDataSetWriterId   ResourceType = "NewDataSetWriterId"
+
+	// resources of the specific services
+	ResourceRead          = ResourceType(Read)
+	ResourceWrite         = ResourceType(Write)
+	ResourceSubscribe     = ResourceType(Subscribe)
+	ResourceUnsubscribe   = ResourceType(Unsubscribe)
+	ResourceGenericMethod = ResourceType(GenericMethod)
 )
 
 var resourceTypes = map[ResourceType]struct{}{
@@ -36,6 +48,16 @@ var resourceTypes = map[ResourceType]struct{}{
 	ResourceSubscriptionList:     {},
 	ResourceInterfaces:           {},
 	ResourceReferenceDesignation: {},
+	ResourceFileUpload:           {},
+	ResourceFileDownload:         {},
+	ResourceFirmwareUpdate:       {},
+	ResourceBlink:                {},
+	ResourceNewDataSetWriterId:   {},
+	ResourceRead:                 {},
+	ResourceWrite:                {},
+	ResourceSubscribe:            {},
+	ResourceUnsubscribe:          {},
+	ResourceGenericMethod:        {},
 }
 
 func ParseResourceType(s string) (*ResourceType, error) {
@@ -86,4 +108,9 @@ var dataSetClassIdMapping = map[ResourceType]string{
 	ResourceSubscriptionList:     "e5d68c47-c276-4929-8ab9-4c1090cac785",
 	ResourceInterfaces:           "96d22d73-bce6-42d3-9949-45e0d04e4d54",
 	ResourceReferenceDesignation: "27a75019-164a-496d-a38b-90e8a55c2cfa",
+	ResourceFileUpload:           "3b4a62ba-026f-4ee8-bc99-3a5f85fc9f3b",
+	ResourceFileDownload:         "760abda2-ba40-4e6e-863a-eea8c002b4e4",
+	ResourceFirmwareUpdate:       "414e26f6-341b-43b7-90fc-bb9e0b1b0866",
+	ResourceBlink:                "3b423a40-a676-4ba0-8017-f0b2cd65bc26",
+	ResourceNewDataSetWriterId:   "2aca55bd-0d6f-41b1-a1c2-2d61afcc21f0",
 }
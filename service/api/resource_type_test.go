@@ -5,7 +5,7 @@ import (
 )
 
 func TestParseResourceType_ValidResourceTypes(t *testing.T) {
-	validResourceTypes := []string{"MAM", "Health", "Config", "License", "LicenseText", "RtLicense", "Data", "Metadata", "Event", "Profile", "PublicationList", "SubscriptionList", "Interfaces", "ReferenceDesignation"}
+	validResourceTypes := []string{"MAM", "Health", "Config", "License", "LicenseText", "RtLicense", "Data", "Metadata", "Event", "Profile", "PublicationList", "SubscriptionList", "Interfaces", "ReferenceDesignation", "FileUpload", "FileDownload", "FirmwareUpdate", "Blink", "NewDataSetWriterId", "Read", "Write", "Subscribe", "Unsubscribe", "GenericMethod"}
 
 	for _, resourceType := range validResourceTypes {
 		_, err := ParseResourceType(resourceType)
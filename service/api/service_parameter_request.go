@@ -1,5 +1,5 @@
 package api
 
 type ServiceParameterRequest struct {
-	MethodsToCall []any `json:"MessagesToCall"`
+	MethodsToCall []CallMethodRequest `json:"MethodsToCall"`
 }
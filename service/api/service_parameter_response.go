@@ -1,5 +1,5 @@
 package api
 
 type ServiceParametersResponse struct {
-	Results []any `json:"Results"`
+	Results []CallMethodResult `json:"Results"`
 }
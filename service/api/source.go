@@ -8,6 +8,12 @@ type ApplicationSource interface {
 	RemoveSource(Oi4Identifier)
 }
 
+// LocalizedSource is implemented by sources which provide resources in several languages
+type LocalizedSource interface {
+	// GetLocalized returns the resource in the locale, false if the resource is not available in the locale
+	GetLocalized(resource ResourceType, filter *Filter, locale string) ([]any, bool)
+}
+
 type AssetSource interface {
 	BaseSource
 
@@ -18,6 +24,9 @@ type BaseSource interface {
 	GetOi4Identifier() *Oi4Identifier
 
 	GetMasterAssetModel() MasterAssetModel
+	// UpdateMasterAssetModel replaces the MAM, e.g. the SoftwareRevision after a firmware update. The identifying
+	// fields of the MAM must not change.
+	UpdateMasterAssetModel(MasterAssetModel)
 
 	GetHealth() Health
 	UpdateHealth(Health)
@@ -26,6 +35,9 @@ type BaseSource interface {
 	UpdateData(data Data, dataTag string)
 
 	GetConfig() PublishConfig
+	// ApplyConfig replaces the config accepted by a Set request, unlike the Update methods it does not publish the
+	// config, as the application answers the request with its CorrelationId
+	ApplyConfig(config PublishConfig)
 
 	GetProfile() Profile
 
@@ -6,3 +6,7 @@ type Subscription interface {
 	GetQoS() byte
 	GetHandler() MessageHandler
 }
+
+type SubscriptionProvider interface {
+	GetSubscriptions() []Subscription
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrTypeMismatch is returned if a value does not match the FieldMetaData of a variable
+var ErrTypeMismatch = errors.New("the value does not match the type of the variable")
+
+// VariableAccess is implemented by the asset sources of OT connectors to expose the variables of a device with the
+// specific services Read, Write, Subscribe, Unsubscribe and GenericMethod. A variable is identified by the Name of its
+// FieldMetaData.
+type VariableAccess interface {
+	// GetVariables returns the metadata of all variables of the device
+	GetVariables() []FieldMetaData
+	// ReadVariable returns the current value of the variable
+	ReadVariable(name string) (any, StatusCode)
+	// WriteVariable writes a value, which has been converted to the type of the variable
+	WriteVariable(name string, value any) StatusCode
+	// CallGenericMethod calls a device specific method, it returns BadMethodInvalid for unknown methods
+	CallGenericMethod(methodId string, inputArguments []any) ([]any, StatusCode)
+}
+
+// ValueRanks of OPC UA, a positive ValueRank is the number of dimensions of an array
+const (
+	// ValueRankScalarOrOneDimension allows a scalar or an array with one dimension
+	ValueRankScalarOrOneDimension int32 = -3
+	// ValueRankAny allows a scalar or an array with any number of dimensions
+	ValueRankAny int32 = -2
+	// ValueRankScalar is the ValueRank of a field, which is not an array
+	ValueRankScalar int32 = -1
+	// ValueRankOneOrMoreDimensions requires an array with any number of dimensions
+	ValueRankOneOrMoreDimensions int32 = 0
+)
+
+var byteStringType = reflect.TypeFor[[]byte]()
+
+var builtInTypes = map[BuiltInDataType]reflect.Type{
+	BuiltInType_Boolean:    reflect.TypeFor[bool](),
+	BuiltInType_SByte:      reflect.TypeFor[int8](),
+	BuiltInType_Byte:       reflect.TypeFor[uint8](),
+	BuiltInType_Int16:      reflect.TypeFor[int16](),
+	BuiltInType_UInt16:     reflect.TypeFor[uint16](),
+	BuiltInType_Int32:      reflect.TypeFor[int32](),
+	BuiltInType_UInt32:     reflect.TypeFor[uint32](),
+	BuiltInType_Int64:      reflect.TypeFor[int64](),
+	BuiltInType_UInt64:     reflect.TypeFor[uint64](),
+	BuiltInType_Float:      reflect.TypeFor[float32](),
+	BuiltInType_Double:     reflect.TypeFor[float64](),
+	BuiltInType_String:     reflect.TypeFor[string](),
+	BuiltInType_DateTime:   reflect.TypeFor[time.Time](),
+	BuiltInType_ByteString: reflect.TypeFor[[]byte](),
+	BuiltInType_StatusCode: reflect.TypeFor[StatusCode](),
+}
+
+// ConvertValue converts a value, e.g. decoded from JSON, into the Go type of the BuiltInType of the field. An array is
+// converted into a slice per dimension. Values out of the range of the type, strings exceeding the MaxStringLength and
+// values not matching the ValueRank are rejected with ErrTypeMismatch. Values of structured BuiltInTypes, e.g.
+// Variant, are returned unchanged.
+func (field FieldMetaData) ConvertValue(value any) (any, error) {
+	elementType, ok := builtInTypes[field.BuiltInType]
+	if !ok {
+		return value, nil
+	}
+
+	if value == nil {
+		return nil, fmt.Errorf("%w: %s requires a value", ErrTypeMismatch, field.Name)
+	}
+
+	dimensions, err := field.dimensions(value, elementType)
+	if err != nil {
+		return nil, err
+	}
+	targetType := elementType
+	for i := 0; i < dimensions; i++ {
+		targetType = reflect.SliceOf(targetType)
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTypeMismatch, err)
+	}
+	target := reflect.New(targetType)
+	if err = json.Unmarshal(encoded, target.Interface()); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTypeMismatch, err)
+	}
+
+	converted := target.Elem().Interface()
+	if err = field.checkLength(target.Elem()); err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
+
+// dimensions returns the number of array dimensions of the value, which must be allowed by the ValueRank
+func (field FieldMetaData) dimensions(value any, elementType reflect.Type) (int, error) {
+	if field.ValueRank > 0 {
+		return int(field.ValueRank), nil
+	}
+
+	dimensions := arrayDimensions(reflect.ValueOf(value), elementType)
+	switch {
+	case field.ValueRank == ValueRankScalar && dimensions > 0,
+		field.ValueRank == ValueRankOneOrMoreDimensions && dimensions == 0,
+		field.ValueRank == ValueRankScalarOrOneDimension && dimensions > 1:
+		return 0, fmt.Errorf("%w: %d dimensions do not match the ValueRank %d of %s", ErrTypeMismatch, dimensions, field.ValueRank, field.Name)
+	case field.ValueRank < ValueRankScalarOrOneDimension:
+		return 0, fmt.Errorf("%w: invalid ValueRank %d of %s", ErrTypeMismatch, field.ValueRank, field.Name)
+	}
+	return dimensions, nil
+}
+
+// arrayDimensions counts the nested arrays of a value by their first elements, a []byte of a ByteString is a scalar
+func arrayDimensions(value reflect.Value, elementType reflect.Type) int {
+	dimensions := 0
+	for value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		if elementType == byteStringType && value.Type() == byteStringType {
+			break
+		}
+		dimensions++
+		if value.Len() == 0 {
+			break
+		}
+		value = reflect.ValueOf(value.Index(0).Interface())
+	}
+	return dimensions
+}
+
+// checkLength verifies the MaxStringLength of the String and ByteString values of all dimensions, 0 if unlimited
+func (field FieldMetaData) checkLength(value reflect.Value) error {
+	if field.MaxStringLength == 0 {
+		return nil
+	}
+
+	switch {
+	case value.Kind() == reflect.String || value.Type() == byteStringType:
+		if value.Len() > int(field.MaxStringLength) {
+			return fmt.Errorf("%w: the value of %s exceeds %d", ErrTypeMismatch, field.Name, field.MaxStringLength)
+		}
+	case value.Kind() == reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			if err := field.checkLength(value.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
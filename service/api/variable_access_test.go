@@ -0,0 +1,66 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestConvertValueToBuiltInType(t *testing.T) {
+	tests := []struct {
+		field    FieldMetaData
+		value    any
+		expected any
+	}{
+		{FieldMetaData{BuiltInType: BuiltInType_Boolean, ValueRank: ValueRankScalar}, true, true},
+		{FieldMetaData{BuiltInType: BuiltInType_SByte, ValueRank: ValueRankScalar}, -12.0, int8(-12)},
+		{FieldMetaData{BuiltInType: BuiltInType_UInt16, ValueRank: ValueRankScalar}, 65535.0, uint16(65535)},
+		{FieldMetaData{BuiltInType: BuiltInType_Double, ValueRank: ValueRankScalar}, 1.5, 1.5},
+		{FieldMetaData{BuiltInType: BuiltInType_String, ValueRank: ValueRankScalar, MaxStringLength: 5}, "valve", "valve"},
+		{FieldMetaData{BuiltInType: BuiltInType_ByteString, ValueRank: ValueRankScalar}, "AQI=", []byte{1, 2}},
+		{FieldMetaData{BuiltInType: BuiltInType_DateTime, ValueRank: ValueRankScalar}, "2024-05-01T10:00:00Z", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: 1}, []any{1.0, 2.0}, []int32{1, 2}},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: 2}, []any{[]any{1.0}, []any{2.0}}, [][]int32{{1}, {2}}},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankOneOrMoreDimensions}, []any{1.0, 2.0}, []int32{1, 2}},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankOneOrMoreDimensions}, []any{[]any{1.0}}, [][]int32{{1}}},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankAny}, 1.0, int32(1)},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankAny}, []any{[]any{1.0}}, [][]int32{{1}}},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankScalarOrOneDimension}, 1.0, int32(1)},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankScalarOrOneDimension}, []any{1.0}, []int32{1}},
+		{FieldMetaData{BuiltInType: BuiltInType_ByteString, ValueRank: ValueRankAny, MaxStringLength: 2}, []byte{1, 2}, []byte{1, 2}},
+		{FieldMetaData{BuiltInType: BuiltInType_ByteString, ValueRank: ValueRankAny}, []any{"AQI="}, [][]byte{{1, 2}}},
+		{FieldMetaData{BuiltInType: BuiltInType_Variant, ValueRank: ValueRankScalar}, map[string]any{"a": 1}, map[string]any{"a": 1}},
+	}
+
+	for _, test := range tests {
+		converted, err := test.field.ConvertValue(test.value)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, converted)
+	}
+}
+
+func TestConvertValueRejectsMismatches(t *testing.T) {
+	tests := []struct {
+		field FieldMetaData
+		value any
+	}{
+		{FieldMetaData{BuiltInType: BuiltInType_Boolean, ValueRank: ValueRankScalar}, "true"},
+		{FieldMetaData{BuiltInType: BuiltInType_Byte, ValueRank: ValueRankScalar}, 256.0},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankScalar}, 1.5},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankScalar}, []any{1.0}},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankScalar}, nil},
+		{FieldMetaData{BuiltInType: BuiltInType_String, ValueRank: ValueRankScalar, MaxStringLength: 4}, "valve"},
+		{FieldMetaData{BuiltInType: BuiltInType_String, ValueRank: 1, MaxStringLength: 4}, []any{"pump", "valve"}},
+		{FieldMetaData{BuiltInType: BuiltInType_String, ValueRank: ValueRankAny, MaxStringLength: 4}, []any{[]any{"pump"}, []any{"valve"}}},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankOneOrMoreDimensions}, 1.0},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: ValueRankScalarOrOneDimension}, []any{[]any{1.0}}},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: 2}, []any{1.0}},
+		{FieldMetaData{BuiltInType: BuiltInType_Int32, ValueRank: -4}, 1.0},
+	}
+
+	for _, test := range tests {
+		_, err := test.field.ConvertValue(test.value)
+		assert.ErrorIs(t, err, ErrTypeMismatch, "%v", test.value)
+	}
+}
@@ -3,8 +3,12 @@ package application
 import (
 	"errors"
 	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/filetransfer"
+	"github.com/OI4/oi4-oec-service-go/service/application/outbox"
+	"github.com/OI4/oi4-oec-service-go/service/application/pagination"
 	pub "github.com/OI4/oi4-oec-service-go/service/application/publication"
 	"github.com/OI4/oi4-oec-service-go/service/application/subscription"
+	"github.com/OI4/oi4-oec-service-go/service/codec"
 	"github.com/OI4/oi4-oec-service-go/service/container"
 	"github.com/OI4/oi4-oec-service-go/service/mqtt"
 	"github.com/OI4/oi4-oec-service-go/service/opc"
@@ -13,6 +17,8 @@ import (
 	"maps"
 	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
@@ -28,6 +34,10 @@ type Oi4ApplicationImpl struct {
 	serviceType   api.ServiceType
 
 	qos byte
+	// maximum size of a NetworkMessage in bytes, 0 if not limited
+	maxPacketSize int
+	// encodes the published NetworkMessages, received messages are decoded with the codec they were encoded with
+	codec api.Codec
 
 	mqttClient api.MqttClient
 
@@ -40,12 +50,60 @@ type Oi4ApplicationImpl struct {
 	subscriptions      map[string]api.Subscription
 	subscriptionsMutex sync.RWMutex
 
+	// retainedTopics contains all topics with a retained message and the source they were published for
+	retainedTopics      map[string]api.Oi4Identifier
+	retainedTopicsMutex sync.Mutex
+
 	applicationSource api.ApplicationSource
 
 	logger *zap.SugaredLogger
 
 	scheduler api.IntervalPublicationScheduler
 
+	// paginator splits large resources into pages and keeps the snapshots of paginated Get requests
+	paginator *pagination.Paginator
+
+	// getCalls are the pending Get requests by the MessageId of their requests
+	getCalls          map[string]*getCall
+	getMutex          sync.Mutex
+	getResponseWindow time.Duration
+
+	// filterHandlers are the handlers of the topic filters subscribed by subscriptions and Get requests
+	filterHandlers map[string]*filterHandler
+	filterMutex    sync.Mutex
+
+	// methods of the application source called by Call requests
+	methods *methodRegistry
+
+	// dataSetWriterIds of the publications of the application and its assets
+	dataSetWriterIds *opc.DataSetWriterIds
+
+	// fileTransferPermission enables the FileUpload and FileDownload services, it decides the access to every file
+	fileTransferPermission filetransfer.PermissionFn
+	fileTransfer           *filetransfer.Service
+
+	// applyConfigFn applies the config of a Set request, the request is rejected if it returns an error
+	applyConfigFn func(source api.BaseSource, config api.PublishConfig) error
+	// configPath is the folder of the persisted configs, empty if not persisted
+	configPath string
+
+	// republish the health of all assets after a reconnect
+	assetHealthOnReconnect bool
+
+	connectionListeners *mqtt.ConnectionListeners
+	// health of the application source while the broker is not reachable, nil if unchanged
+	offlineHealth       *api.HealthEnum
+	offlineHealthMutex  sync.Mutex
+	healthBeforeOffline *api.Health
+
+	// outbox stores publications while the broker is not reachable, nil if disabled
+	outboxConfig      *outbox.Config
+	outbox            *outbox.Outbox
+	outboxReplaying   atomic.Bool
+	outboxHealthMutex sync.Mutex
+	// health of the application before messages got lost because of an outbox overflow
+	healthBeforeOverflow *api.Health
+
 	createMqttClientFn func(options *api.MqttClientOptions) (api.MqttClient, error)
 }
 
@@ -59,7 +117,8 @@ func CreateNewApplication(serviceType api.ServiceType, applicationSource api.App
 		oi4Identifier: mam.ToOi4Identifier(),
 		serviceType:   serviceType,
 
-		qos: 1,
+		qos:   1,
+		codec: codec.Default,
 
 		assets:     make(map[api.Oi4Identifier]*AssetImpl),
 		assetMutex: sync.RWMutex{},
@@ -70,9 +129,21 @@ func CreateNewApplication(serviceType api.ServiceType, applicationSource api.App
 		subscriptions:      make(map[string]api.Subscription),
 		subscriptionsMutex: sync.RWMutex{},
 
+		retainedTopics: make(map[string]api.Oi4Identifier),
+
+		connectionListeners: mqtt.NewConnectionListeners(),
+
 		applicationSource: applicationSource,
 		logger:            logger,
 		scheduler:         scheduler,
+		paginator:         pagination.NewPaginator(pagination.DefaultPageSize, pagination.DefaultCursorTtl),
+
+		methods:          newMethodRegistry(),
+		dataSetWriterIds: opc.NewDataSetWriterIds(),
+
+		getCalls:          make(map[string]*getCall),
+		getResponseWindow: DefaultGetResponseWindow,
+		filterHandlers:    make(map[string]*filterHandler),
 	}
 	applicationSource.SetOi4Application(application)
 
@@ -103,20 +174,32 @@ func (app *Oi4ApplicationImpl) GetIntervalPublicationScheduler() api.IntervalPub
 	return app.scheduler
 }
 
+func (app *Oi4ApplicationImpl) GetPaginator() api.Paginator {
+	return app.paginator
+}
+
 // Start an application and connect to a broker
 func (app *Oi4ApplicationImpl) Start(storage container.Storage) error {
-	brokerConfig := storage.MessageBusStorage.BrokerConfiguration
-	credentials := storage.SecretStorage.MqttCredentials
-	pwd, _ := credentials.Password()
-	mqttClientOptions := &api.MqttClientOptions{
-		Host:     brokerConfig.Address,
-		Port:     int(brokerConfig.SecurePort),
-		Tls:      true,
-		Username: credentials.Username(),
-		Password: pwd,
-		ClientId: app.oi4Identifier.SerialNumber,
+	// the broker configuration defines the MaxPacketSize in KiB
+	app.maxPacketSize = int(storage.MessageBusStorage.BrokerConfiguration.MaxPacketSize) * 1024
+
+	if err := app.openOutbox(storage); err != nil {
+		return err
+	}
+	app.openConfigStorage(storage)
+	app.restoreConfigs()
+	if err := app.openFileTransfer(storage); err != nil {
+		return err
+	}
+	if err := app.registerDataSetWriterIdService(); err != nil {
+		return err
 	}
 
+	mqttClientOptions := newMqttClientOptions(storage, app.oi4Identifier.SerialNumber)
+	mqttClientOptions.WillFn = app.lastWill
+	mqttClientOptions.ConnectionListener = app.onConnectionStateChange
+	mqttClientOptions.Codec = app.codec
+
 	var err error
 	if app.mqttClient, err = app.newMqttClient(mqttClientOptions); err != nil {
 		return err
@@ -125,23 +208,48 @@ func (app *Oi4ApplicationImpl) Start(storage container.Storage) error {
 	if err = app.mqttClient.RegisterGetHandler(app.serviceType, *app.mam.ToOi4Identifier(), 1, app.GetHandler()); err != nil {
 		return err
 	}
+	if err = app.subscribeSetConfig(); err != nil {
+		return err
+	}
+	if err = app.subscribeDel(); err != nil {
+		return err
+	}
+	if err = app.subscribeCall(); err != nil {
+		return err
+	}
+
+	// messages stored by a previous run are sent before the new publications
+	app.replayOutbox()
 
 	if err = app.registerPublications(); err != nil {
 		return err
 	}
 
+	// subscriptions registered before the start are subscribed now, the client restores them after a reconnect
+	app.subscriptionsMutex.RLock()
+	for _, current := range app.subscriptions {
+		if err = app.subscribe(current); err != nil {
+			app.subscriptionsMutex.RUnlock()
+			return err
+		}
+	}
+	app.subscriptionsMutex.RUnlock()
+
 	app.GetIntervalPublicationScheduler().Start()
 
 	return nil
 }
 
 // Stop application and shutdown all publications and assets
+// Retained messages of the application and its assets are cleared, as they would describe a no longer running application.
 func (app *Oi4ApplicationImpl) Stop() {
 	for _, publication := range app.GetPublications() {
 		publication.Stop()
 	}
+	app.clearRetainedTopics(nil)
 	app.sendGracefulShutdown()
 	app.mqttClient.Stop()
+	app.closeFileTransfer()
 }
 
 // RegisterPublication Register a publisher for the specific application
@@ -188,14 +296,24 @@ func (app *Oi4ApplicationImpl) GetPublications() []api.Publication {
 	return result
 }
 
-// RegisterAsset Add new asset to the application
+// RemovePublication stops a publication and removes it from the application
+func (app *Oi4ApplicationImpl) RemovePublication(publication api.Publication) {
+	app.publicationMutex.Lock()
+	defer app.publicationMutex.Unlock()
+
+	removePublication(app.publications, publication)
+}
+
+// RegisterAsset Add new asset to the application, its persisted config is restored. The config of an asset registered
+// before Start is restored on Start.
 func (app *Oi4ApplicationImpl) RegisterAsset(asset *AssetImpl) {
 	app.assetMutex.RLock()
-	defer app.assetMutex.RUnlock()
-
 	asset.setParent(app)
 	oi4Id := asset.mam.ToOi4Identifier()
 	app.assets[*oi4Id] = asset
+	app.assetMutex.RUnlock()
+
+	app.restoreConfig(asset.source)
 }
 
 // RemoveAsset remove an asset from the application
@@ -205,6 +323,7 @@ func (app *Oi4ApplicationImpl) RemoveAsset(asset *AssetImpl) {
 
 	asset.setParent(nil)
 	delete(app.assets, *asset.mam.ToOi4Identifier())
+	app.clearRetainedTopics(asset.mam.ToOi4Identifier())
 }
 
 func (app *Oi4ApplicationImpl) UpdateHealth(health api.Health) {
@@ -216,10 +335,14 @@ func (app *Oi4ApplicationImpl) GetMam() *api.MasterAssetModel {
 }
 
 func (app *Oi4ApplicationImpl) SendPublicationMessage(publication api.PublicationMessage) {
-	if app.mqttClient == nil || publication.Content == nil || len(publication.Content) == 0 {
+	if app.mqttClient == nil || (len(publication.Content) == 0 && publication.Pagination == nil) {
 		return
 	}
 
+	if publication.DataSetWriterId == 0 {
+		publication.DataSetWriterId = app.GetDataSetWriterId(publication.Resource, publication.Source, publication.Filter)
+	}
+
 	// Deal with combined messages
 	//var source *api.Oi4Identifier
 	//if publication.source != nil &&
@@ -237,24 +360,55 @@ func (app *Oi4ApplicationImpl) SendPublicationMessage(publication api.Publicatio
 		api.MethodPub,
 		publication.Resource,
 		source,
-		nil,
+		publication.Category,
 		publication.Filter,
 	)
 
-	err := app.mqttClient.PublishResource(topic.ToString(), app.qos, opc.CreateNetworkMessage(app.mam.ToOi4Identifier(), app.serviceType, publication))
+	networkMessages, err := opc.CreateNetworkMessages(app.mam.ToOi4Identifier(), app.serviceType, publication, app.maxPacketSize, app.codec.Marshal)
 	if err != nil {
-		return
+		// oversized DataSetMessages are still sent with a bad status code, so the consumer is informed
+		app.logger.Errorf("Failed to fit publication for topic %s into the max packet size: %v", topic.ToString(), err)
+	}
+
+	publishOptions := make([]api.PublishOption, 0)
+	if publication.MessageExpiry > 0 {
+		publishOptions = append(publishOptions, api.WithMessageExpiry(publication.MessageExpiry))
+	}
+
+	for i, networkMessage := range networkMessages {
+		// the broker keeps a single message per topic, so only the last part of a split resource is retained
+		retained := publication.Retained && i == len(networkMessages)-1
+		if app.storeInOutbox(topic.ToString(), retained, publication.MessageExpiry, networkMessage) {
+			continue
+		}
+		err = app.mqttClient.PublishResource(topic.ToString(), app.qos, retained, networkMessage, publishOptions...)
+		if err != nil {
+			app.logger.Warnf("Failed to publish message to topic %s: %v", topic.ToString(), err)
+			return
+		}
+	}
+	app.logger.Debugf("Published %d message(s) to topic: %s", len(networkMessages), topic.ToString())
+
+	if publication.Retained && source != nil {
+		app.retainedTopicsMutex.Lock()
+		app.retainedTopics[topic.ToString()] = *source
+		app.retainedTopicsMutex.Unlock()
 	}
-	app.logger.Debugf("Published message to topic: %s", topic.ToString())
 
 }
 
 func (app *Oi4ApplicationImpl) SendGetMessage(topic string, getMessage api.GetMessage) error {
-	return app.mqttClient.PublishResource(topic, app.qos, getMessage)
+	return app.mqttClient.PublishResource(topic, app.qos, false, getMessage)
 }
 
 func (app *Oi4ApplicationImpl) GetHandler() api.MessageHandler {
-	return subscription.NewMessageHandler(app, func(resource api.ResourceType, source *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+	return subscription.NewMessageHandler(app, func(resource api.ResourceType, source *api.Oi4Identifier, networkMessage api.NetworkMessage, topic *tp.Topic) {
+		request, err := newGetRequest(topic, networkMessage)
+		if err != nil {
+			app.logger.Infof("Invalid Get request %s on topic %s: %v", networkMessage.MessageId, topic.ToString(), err)
+			return
+		}
+
 		sources := make([]api.BaseSource, 0)
 		if source == nil {
 			sources = append(sources, app.applicationSource)
@@ -269,20 +423,12 @@ func (app *Oi4ApplicationImpl) GetHandler() api.MessageHandler {
 			}
 		}
 
-		if sources == nil || len(sources) == 0 {
-			return
-		}
-
 		for _, current := range sources {
-			var filter api.Filter
-			// TODO retrieve the topic filter as input
-			if len(networkMessage.Messages) > 0 {
-				filter = networkMessage.Messages[0].Filter
+			for _, publication := range app.getRequestedPublications(current, resource, request.Filter) {
+				publication.TriggerRequestedPublication(request)
 			}
-
-			app.triggerSourcePublication(current, resource, &filter, api.OnRequest, &networkMessage.MessageId)
 		}
-	})
+	}, subscription.WithSkipOwnMessage(false)) // the topic of a Get request contains the requested application
 }
 
 func (app *Oi4ApplicationImpl) ResourceChanged(resource api.ResourceType, source api.BaseSource, filter *api.Filter) {
@@ -295,7 +441,34 @@ func (app *Oi4ApplicationImpl) RegisterSubscription(subscription api.Subscriptio
 
 	app.subscriptions[subscription.GetID()] = subscription
 
-	return app.mqttClient.Subscribe(subscription)
+	if app.mqttClient == nil {
+		// subscribed on start
+		return nil
+	}
+
+	return app.subscribe(subscription)
+}
+
+// RemoveSubscription unsubscribes from the topic of a subscription and removes it from the application
+func (app *Oi4ApplicationImpl) RemoveSubscription(subscription api.Subscription) error {
+	app.subscriptionsMutex.Lock()
+	defer app.subscriptionsMutex.Unlock()
+
+	delete(app.subscriptions, subscription.GetID())
+
+	if app.mqttClient == nil {
+		return nil
+	}
+
+	return app.unsubscribe(subscription.GetTopic())
+}
+
+// GetSubscriptions Return all registered subscriptions
+func (app *Oi4ApplicationImpl) GetSubscriptions() []api.Subscription {
+	app.subscriptionsMutex.RLock()
+	defer app.subscriptionsMutex.RUnlock()
+
+	return slices.Collect(maps.Values(app.subscriptions))
 }
 
 func (app *Oi4ApplicationImpl) registerPublications() error {
@@ -327,12 +500,24 @@ func (app *Oi4ApplicationImpl) registerPublications() error {
 		}
 	}
 
+	err = app.RegisterPublication(pub.NewResourcePublication(app, app.applicationSource, api.ResourceConfig))
+
+	if err != nil {
+		return err
+	}
+
 	err = app.RegisterPublication(pub.NewResourcePublication(app, app.applicationSource, api.ResourcePublicationList))
 
 	if err != nil {
 		return err
 	}
 
+	err = app.RegisterPublication(pub.NewResourcePublication(app, app.applicationSource, api.ResourceSubscriptionList))
+
+	if err != nil {
+		return err
+	}
+
 	err = app.RegisterPublication(pub.NewBuilder(app).
 		Oi4Source(app.applicationSource).
 		Resource(api.ResourceProfile).
@@ -347,32 +532,42 @@ func (app *Oi4ApplicationImpl) registerPublications() error {
 }
 
 func (app *Oi4ApplicationImpl) triggerSourcePublication(source api.BaseSource, resource api.ResourceType, filter *api.Filter, trigger api.Trigger, correlationId *string) {
-	var publications []api.Publication
-	if source.Equals(app.applicationSource) {
-		publications = getPublications(app.publications, resource, filter)
-	} else {
-		var asset *AssetImpl
+	publications := getPublications(app.sourcePublications(source), resource, filter)
 
-		for _, as := range app.assets {
-			if as.source.Equals(source) {
-				asset = as
-			}
-		}
+	for _, publication := range publications {
+		publication.TriggerPublication(trigger, correlationId)
+	}
+}
 
-		if asset == nil {
-			return
-		}
+// getRequestedPublications returns the publications answering a Get request. A filter without a dedicated
+// publication is answered by the publications of the resource without filter, which pass the filter to the source.
+func (app *Oi4ApplicationImpl) getRequestedPublications(source api.BaseSource, resource api.ResourceType, filter *api.Filter) []api.Publication {
+	publications := app.sourcePublications(source)
+	if requested := getPublications(publications, resource, filter); len(requested) > 0 || filter == nil {
+		return requested
+	}
 
-		publications = getPublications(asset.publications, resource, filter)
+	result := make([]api.Publication, 0)
+	for _, publication := range publications[resource] {
+		if publication.GetFilter() == nil {
+			result = append(result, publication)
+		}
 	}
+	return result
+}
 
-	if publications == nil || len(publications) == 0 {
-		return
+// sourcePublications returns the publications of the application source or of an asset
+func (app *Oi4ApplicationImpl) sourcePublications(source api.BaseSource) map[api.ResourceType][]api.Publication {
+	if source.Equals(app.applicationSource) {
+		return app.publications
 	}
 
-	for _, publication := range publications {
-		publication.TriggerPublication(trigger, correlationId)
+	for _, asset := range app.assets {
+		if asset.source.Equals(source) {
+			return asset.publications
+		}
 	}
+	return nil
 }
 
 func (app *Oi4ApplicationImpl) shouldPublicate(trigger api.Trigger, publication *api.PublicationList) bool {
@@ -410,6 +605,68 @@ func (app *Oi4ApplicationImpl) sendGracefulShutdown() {
 	})
 }
 
+// lastWill creates a FAILURE health for the application, which is published by the broker if the connection is lost
+func (app *Oi4ApplicationImpl) lastWill() (*api.MqttWill, error) {
+	oi4Identifier := app.mam.ToOi4Identifier()
+	topic := tp.NewTopic(app.serviceType, *oi4Identifier, api.MethodPub, api.ResourceHealth, oi4Identifier, nil, nil)
+
+	networkMessage := opc.CreateNetworkMessage(oi4Identifier, app.serviceType, api.PublicationMessage{
+		Resource:        api.ResourceHealth,
+		Source:          oi4Identifier,
+		DataSetWriterId: app.GetDataSetWriterId(api.ResourceHealth, oi4Identifier, nil),
+		Content: []api.PublicationContent{
+			{
+				Data: &api.Health{Health: api.Health_Failure, HealthScore: 0},
+			},
+		},
+	})
+
+	return &api.MqttWill{
+		Topic:    topic.ToString(),
+		Qos:      app.qos,
+		Retained: true,
+		Payload:  networkMessage,
+	}, nil
+}
+
+// onReconnect replaces a possibly published last will with the current health
+func (app *Oi4ApplicationImpl) onReconnect() {
+	app.logger.Info("Connection to broker restored")
+	app.replayOutbox()
+	app.ResourceChanged(api.ResourceHealth, app.applicationSource, nil)
+
+	if !app.assetHealthOnReconnect {
+		return
+	}
+
+	app.assetMutex.RLock()
+	defer app.assetMutex.RUnlock()
+	for _, asset := range app.assets {
+		app.ResourceChanged(api.ResourceHealth, asset.source, nil)
+	}
+}
+
+// clearRetainedTopics removes the retained messages of the given source or of all sources if nil
+func (app *Oi4ApplicationImpl) clearRetainedTopics(source *api.Oi4Identifier) {
+	if app.mqttClient == nil {
+		return
+	}
+
+	app.retainedTopicsMutex.Lock()
+	defer app.retainedTopicsMutex.Unlock()
+
+	for topic, topicSource := range app.retainedTopics {
+		if source != nil && !source.Equals(&topicSource) {
+			continue
+		}
+		if err := app.mqttClient.ClearRetained(topic); err != nil {
+			app.logger.Warnf("Failed to clear retained message of topic %s: %v", topic, err)
+			continue
+		}
+		delete(app.retainedTopics, topic)
+	}
+}
+
 func (app *Oi4ApplicationImpl) newMqttClient(options *api.MqttClientOptions) (api.MqttClient, error) {
 	if app.createMqttClientFn != nil {
 		return app.createMqttClientFn(options)
@@ -417,6 +674,18 @@ func (app *Oi4ApplicationImpl) newMqttClient(options *api.MqttClientOptions) (ap
 	return mqtt.NewClient(options)
 }
 
+// removePublication stops the publication and removes it from the publications of its resource
+func removePublication(publications map[api.ResourceType][]api.Publication, publication api.Publication) {
+	resourcePublications := publications[publication.GetResource()]
+	for i, current := range resourcePublications {
+		if current == publication {
+			current.Stop()
+			publications[publication.GetResource()] = slices.Delete(resourcePublications, i, i+1)
+			return
+		}
+	}
+}
+
 func getPublications(publications map[api.ResourceType][]api.Publication, resource api.ResourceType, filter *api.Filter) []api.Publication {
 	resourcePublications := publications[resource]
 	if resourcePublications == nil || filter == nil {
@@ -449,3 +718,70 @@ func WithQos(qos byte) Option {
 		app.qos = qos
 	}
 }
+
+// WithCodec selects the codec of the published payloads, JSON is used by default as defined by the OI4 guideline
+func WithCodec(payloadCodec api.Codec) Option {
+	return func(app *Oi4ApplicationImpl) {
+		app.codec = codec.OrDefault(payloadCodec)
+	}
+}
+
+// WithOfflineHealth sets the health of the application source to FAILURE or CHECK_FUNCTION while the broker is not
+// reachable, the previous health is restored after the reconnect. Any other health is ignored with a warning.
+func WithOfflineHealth(health api.HealthEnum) Option {
+	return func(app *Oi4ApplicationImpl) {
+		if health != api.Health_Failure && health != api.Health_CheckFunction {
+			app.logger.Warnf("Offline health %s is not supported, use %s or %s", health, api.Health_Failure, api.Health_CheckFunction)
+			return
+		}
+		app.offlineHealth = &health
+	}
+}
+
+// WithOutbox stores QoS 1 and 2 publications below the data path of the container while the broker is not reachable.
+// The messages are replayed in order after the reconnect, also after a restart of the application.
+func WithOutbox(config outbox.Config) Option {
+	return func(app *Oi4ApplicationImpl) {
+		app.outboxConfig = &config
+	}
+}
+
+// WithAssetHealthOnReconnect republishes the health of every registered asset once the application reconnects
+func WithAssetHealthOnReconnect(enabled bool) Option {
+	return func(app *Oi4ApplicationImpl) {
+		app.assetHealthOnReconnect = enabled
+	}
+}
+
+// WithPagination splits resources with more than pageSize elements into pages, 0 disables the pagination of
+// resources which are not requested with a PaginationRequest. The snapshot of a paginated Get request is kept for
+// cursorTtl after the last page request.
+func WithPagination(pageSize int, cursorTtl time.Duration) Option {
+	return func(app *Oi4ApplicationImpl) {
+		app.paginator = pagination.NewPaginator(pageSize, cursorTtl)
+	}
+}
+
+// WithFileTransfer provides the FileUpload and FileDownload services for files in the directory "files" below the data
+// path of the container. The permission function decides the access to every file, the services are disabled without
+// a data path.
+func WithFileTransfer(permission filetransfer.PermissionFn) Option {
+	return func(app *Oi4ApplicationImpl) {
+		app.fileTransferPermission = permission
+	}
+}
+
+// WithApplyConfigFn sets the function applying the config of a Set request, which was validated against the config of
+// the source. The request is rejected if the function returns an error.
+func WithApplyConfigFn(fn func(source api.BaseSource, config api.PublishConfig) error) Option {
+	return func(app *Oi4ApplicationImpl) {
+		app.applyConfigFn = fn
+	}
+}
+
+// WithGetResponseWindow sets the time a Get request waits for further responses after the last one
+func WithGetResponseWindow(window time.Duration) Option {
+	return func(app *Oi4ApplicationImpl) {
+		app.getResponseWindow = window
+	}
+}
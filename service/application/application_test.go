@@ -1,15 +1,35 @@
 package application
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/OI4/dnp-encoder-go"
 	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/blink"
+	"github.com/OI4/oi4-oec-service-go/service/application/filetransfer"
+	"github.com/OI4/oi4-oec-service-go/service/application/firmware"
+	"github.com/OI4/oi4-oec-service-go/service/application/outbox"
+	pub "github.com/OI4/oi4-oec-service-go/service/application/publication"
 	"github.com/OI4/oi4-oec-service-go/service/application/source"
+	"github.com/OI4/oi4-oec-service-go/service/application/specific"
+	"github.com/OI4/oi4-oec-service-go/service/application/subscription"
+	"github.com/OI4/oi4-oec-service-go/service/codec"
 	"github.com/OI4/oi4-oec-service-go/service/container"
+	"github.com/OI4/oi4-oec-service-go/service/mqtt/memory"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestWithMockMqttClient(t *testing.T) {
@@ -45,10 +65,100 @@ func TestWithMockMqttClient(t *testing.T) {
 	assert.True(t, mqttClientMock.RegisterGetHandlerCalled)
 }
 
+func TestRetainedMessagesClearedOnAssetRemoval(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+
+	applicationSource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+
+	retained := make(map[string]bool)
+	mqttClientMock := &MqttClientMock{
+		PublishResourceWithRetainFunc: func(topic string, r bool, _ interface{}) error {
+			if r {
+				retained[topic] = true
+			}
+			return nil
+		},
+	}
+	app := CreateNewApplication(api.ServiceTypeUtility, applicationSource, logger.Sugar(), WithMqttClientFn(func(options *api.MqttClientOptions) (api.MqttClient, error) {
+		return mqttClientMock, nil
+	}))
+	require.NoError(t, app.Start(testStorage()))
+
+	assetSource := source.NewAssetSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "2"})
+	asset := CreateNewAsset(assetSource, app)
+	app.RegisterAsset(asset)
+
+	assetMamTopic := "Oi4/Utility/acme.com///1/Pub/MAM/acme.com///2"
+	assert.True(t, retained[assetMamTopic])
+
+	app.RemoveAsset(asset)
+	assert.Equal(t, []string{assetMamTopic}, mqttClientMock.ClearedTopics)
+}
+
+func TestRetainedPublications(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+
+	applicationSource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+
+	var retained []bool
+	mqttClientMock := &MqttClientMock{
+		PublishResourceWithRetainFunc: func(_ string, r bool, _ interface{}) error {
+			retained = append(retained, r)
+			return nil
+		},
+	}
+	app := CreateNewApplication(api.ServiceTypeUtility, applicationSource, logger.Sugar(), WithMqttClientFn(func(options *api.MqttClientOptions) (api.MqttClient, error) {
+		return mqttClientMock, nil
+	}))
+	require.NoError(t, app.Start(testStorage()))
+	mam := app.publications[api.ResourceMam][0]
+
+	retained = nil
+	mam.TriggerPublication(api.OnRequest, nil)
+	assert.Equal(t, []bool{true}, retained)
+
+	// a response to another client is not retained
+	retained = nil
+	correlationId := "get"
+	mam.TriggerPublication(api.OnRequest, &correlationId)
+	mam.TriggerRequestedPublication(api.GetRequest{MessageId: correlationId})
+	mam.TriggerRequestedPublication(api.GetRequest{MessageId: correlationId, Filter: api.NewFilter("other")})
+	assert.Equal(t, []bool{false, false, false}, retained)
+
+	// a resource split into several messages retains the last one only
+	retained = nil
+	app.maxPacketSize = 1024
+	content := make([]api.PublicationContent, 20)
+	for i := range content {
+		content[i] = api.PublicationContent{Data: &api.SimpleData{Value: strings.Repeat("x", 100)}}
+	}
+	app.SendPublicationMessage(api.PublicationMessage{Resource: api.ResourceData, Source: app.oi4Identifier, Content: content, Retained: true})
+	require.Greater(t, len(retained), 1)
+	assert.Equal(t, append(make([]bool, len(retained)-1), true), retained)
+}
+
+func testStorage() container.Storage {
+	return container.Storage{
+		MessageBusStorage: &container.MessageBusStorage{
+			BrokerConfiguration: &container.BrokerConfiguration{
+				Address:    "mqtt.example.com",
+				SecurePort: 8883,
+			},
+		},
+		SecretStorage: &container.SecretStorage{
+			MqttCredentials: url.UserPassword("testuser", "testpassword"),
+		},
+	}
+}
+
 type MqttClientMock struct {
-	PublishResourceFunc      func(topic string, msg interface{}) error
-	SubscribeFunc            func(sub api.Subscription) error
-	RegisterGetHandlerCalled bool
+	PublishResourceFunc           func(topic string, msg interface{}) error
+	PublishResourceWithRetainFunc func(topic string, retained bool, msg interface{}) error
+	SubscribeFunc                 func(sub api.Subscription) error
+	RegisterGetHandlerCalled      bool
+	ClearedTopics                 []string
 }
 
 func (m *MqttClientMock) RegisterGetHandler(_ api.ServiceType, _ api.Oi4Identifier, _ byte, _ api.MessageHandler) error {
@@ -60,11 +170,30 @@ func (m *MqttClientMock) SubscribeToTopic(_ string, _ byte, _ api.MessageHandler
 	panic("implement me")
 }
 
+func (m *MqttClientMock) Unsubscribe(_ string) error {
+	return nil
+}
+
 func (m *MqttClientMock) Stop() {
 	panic("implement me")
 }
 
-func (m *MqttClientMock) PublishResource(topic string, _ byte, msg interface{}) error {
+func (m *MqttClientMock) IsConnected() bool {
+	return true
+}
+
+func (m *MqttClientMock) AddConnectionListener(_ api.ConnectionListener) {
+}
+
+func (m *MqttClientMock) ClearRetained(topic string) error {
+	m.ClearedTopics = append(m.ClearedTopics, topic)
+	return nil
+}
+
+func (m *MqttClientMock) PublishResource(topic string, _ byte, retained bool, msg interface{}, _ ...api.PublishOption) error {
+	if m.PublishResourceWithRetainFunc != nil {
+		return m.PublishResourceWithRetainFunc(topic, retained, msg)
+	}
 	if m.PublishResourceFunc != nil {
 		return m.PublishResourceFunc(topic, msg)
 	}
@@ -77,3 +206,1004 @@ func (m *MqttClientMock) Subscribe(sub api.Subscription) error {
 	}
 	return nil
 }
+
+func TestLastWillIsFailureHealth(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+
+	applicationSource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	app := CreateNewApplication(api.ServiceTypeUtility, applicationSource, logger.Sugar())
+
+	will, err := app.lastWill()
+	require.NoError(t, err)
+	assert.Equal(t, "Oi4/Utility/acme.com///1/Pub/Health/acme.com///1", will.Topic)
+	assert.True(t, will.Retained)
+
+	networkMessage, ok := will.Payload.(*api.NetworkMessage)
+	require.True(t, ok)
+	require.Len(t, networkMessage.Messages, 1)
+	assert.NotNil(t, networkMessage.Messages[0].Timestamp)
+	assert.Equal(t, &api.Health{Health: api.Health_Failure, HealthScore: 0}, networkMessage.Messages[0].Payload)
+}
+
+func TestApplicationsOnMemoryBus(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+	bus := memory.NewBus()
+
+	utilitySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	utility := CreateNewApplication(api.ServiceTypeUtility, utilitySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, utility.Start(testStorage()))
+
+	registrySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "2"})
+	registry := CreateNewApplication(api.ServiceTypeRegistry, registrySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, registry.Start(testStorage()))
+	defer registry.Stop()
+
+	received := make(map[api.ResourceType][]api.NetworkMessage)
+	handler := subscription.NewMessageHandler(registry, func(resource api.ResourceType, _ *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+		received[resource] = append(received[resource], networkMessage)
+	})
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription("Oi4/+/+/+/+/+/Pub/#", handler)))
+
+	// the retained MAM of the utility is delivered on subscription
+	require.Len(t, received[api.ResourceMam], 1)
+	assert.Empty(t, received[api.ResourceHealth])
+
+	// the last will of the utility reports its failure
+	utility.mqttClient.(*memory.Client).SimulateConnectionLoss()
+	require.Len(t, received[api.ResourceHealth], 1)
+	health := received[api.ResourceHealth][0].Messages[0].Payload.(map[string]interface{})
+	assert.Equal(t, string(api.Health_Failure), health["Health"])
+
+	// after the reconnect the utility replaces its failure health
+	require.NoError(t, utility.mqttClient.(*memory.Client).Reconnect())
+	require.Len(t, received[api.ResourceHealth], 2)
+
+	utility.Stop()
+	assert.Nil(t, bus.Retained("Oi4/Utility/acme.com///1/Pub/MAM/acme.com///1"))
+}
+
+func TestApplicationWithCborCodec(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+	bus := memory.NewBus()
+
+	utilitySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	utility := CreateNewApplication(api.ServiceTypeUtility, utilitySource, logger.Sugar(), WithMqttClientFn(bus.NewClient), WithCodec(codec.Cbor))
+	require.NoError(t, utility.Start(testStorage()))
+
+	mamTopic := "Oi4/Utility/acme.com///1/Pub/MAM/acme.com///1"
+	assert.Equal(t, codec.Cbor, codec.Detect(bus.Retained(mamTopic)))
+
+	// a receiver with the default codec recognizes the CBOR payload
+	registrySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "2"})
+	registry := CreateNewApplication(api.ServiceTypeRegistry, registrySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, registry.Start(testStorage()))
+
+	var mam map[string]interface{}
+	handler := subscription.NewMessageHandler(registry, func(_ api.ResourceType, _ *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+		mam = networkMessage.Messages[0].Payload.(map[string]interface{})
+	})
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription(mamTopic, handler)))
+	require.NotNil(t, mam)
+	assert.Equal(t, "acme.com", mam["ManufacturerUri"])
+}
+
+func startOutboxApplication(t *testing.T, bus *memory.Bus, config outbox.Config) (*Oi4ApplicationImpl, api.ApplicationSource) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+
+	applicationSource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	app := CreateNewApplication(api.ServiceTypeUtility, applicationSource, logger.Sugar(), WithMqttClientFn(bus.NewClient), WithOutbox(config))
+
+	storage := testStorage()
+	storage.ApplicationSpecificStorages = &container.ApplicationSpecificStorages{DataPath: t.TempDir()}
+	require.NoError(t, app.Start(storage))
+	return app, applicationSource
+}
+
+func TestOutboxReplaysPublicationsAfterReconnect(t *testing.T) {
+	bus := memory.NewBus()
+	app, applicationSource := startOutboxApplication(t, bus, outbox.Config{})
+
+	observerClient, err := bus.NewClient(&api.MqttClientOptions{})
+	require.NoError(t, err)
+	messageIds := make([]string, 0)
+	handler := subscription.NewMessageHandler(app, func(resource api.ResourceType, _ *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+		if resource == api.ResourceMam {
+			messageIds = append(messageIds, networkMessage.MessageId)
+		}
+	}, subscription.WithSkipOwnMessage(false))
+	require.NoError(t, observerClient.SubscribeToTopic("Oi4/Utility/+/+/+/+/Pub/MAM/#", 1, handler))
+	require.Len(t, messageIds, 1)
+
+	client := app.mqttClient.(*memory.Client)
+	client.SimulateConnectionLoss()
+	app.ResourceChanged(api.ResourceMam, applicationSource, nil)
+	app.ResourceChanged(api.ResourceMam, applicationSource, nil)
+	assert.Equal(t, 2, app.outbox.Len())
+	assert.Len(t, messageIds, 1)
+
+	require.NoError(t, client.Reconnect())
+	assert.Equal(t, 0, app.outbox.Len())
+	require.Len(t, messageIds, 3)
+	assert.NotEqual(t, messageIds[1], messageIds[2])
+}
+
+func TestOutboxKeepsMessagesOfAnInterruptedReplay(t *testing.T) {
+	bus := memory.NewBus()
+	app, applicationSource := startOutboxApplication(t, bus, outbox.Config{})
+	client := app.mqttClient.(*memory.Client)
+
+	observerClient, err := bus.NewClient(&api.MqttClientOptions{})
+	require.NoError(t, err)
+	received := 0
+	interrupt := false
+	handler := subscription.NewMessageHandler(app, func(resource api.ResourceType, _ *api.Oi4Identifier, _ api.NetworkMessage, _ *tp.Topic) {
+		if resource != api.ResourceMam {
+			return
+		}
+		received++
+		if interrupt {
+			interrupt = false
+			client.SimulateConnectionLoss()
+		}
+	}, subscription.WithSkipOwnMessage(false))
+	require.NoError(t, observerClient.SubscribeToTopic("Oi4/Utility/+/+/+/+/Pub/MAM/#", 1, handler))
+	require.Equal(t, 1, received)
+
+	client.SimulateConnectionLoss()
+	app.ResourceChanged(api.ResourceMam, applicationSource, nil)
+	app.ResourceChanged(api.ResourceMam, applicationSource, nil)
+	require.Equal(t, 2, app.outbox.Len())
+
+	// the connection is lost while the first message is replayed, so no message is removed and the health of the
+	// reconnect is stored behind them
+	interrupt = true
+	require.NoError(t, client.Reconnect())
+	assert.Equal(t, 2, received)
+	assert.Equal(t, 3, app.outbox.Len())
+
+	require.NoError(t, client.Reconnect())
+	assert.Equal(t, 4, received)
+	assert.Equal(t, 0, app.outbox.Len())
+}
+
+func TestOutboxKeepsMessageExpiry(t *testing.T) {
+	bus := memory.NewBus()
+	app, _ := startOutboxApplication(t, bus, outbox.Config{})
+	client := app.mqttClient.(*memory.Client)
+
+	observerClient, err := bus.NewClient(&api.MqttClientOptions{})
+	require.NoError(t, err)
+	var expiry *uint32
+	handler := subscription.NewMessageHandlerWithProperties(app, func(_ api.ResourceType, _ *api.Oi4Identifier, _ api.NetworkMessage, _ *tp.Topic, properties *api.MessageProperties) {
+		require.NotNil(t, properties)
+		expiry = properties.MessageExpiry
+	}, subscription.WithSkipOwnMessage(false))
+	require.NoError(t, observerClient.SubscribeToTopic("Oi4/Utility/+/+/+/+/Pub/Data/#", 1, handler))
+
+	client.SimulateConnectionLoss()
+	app.SendPublicationMessage(api.PublicationMessage{
+		Resource:      api.ResourceData,
+		Source:        app.oi4Identifier,
+		Content:       []api.PublicationContent{{Data: map[string]any{"Pv": 1.0}}},
+		MessageExpiry: time.Minute,
+	})
+	require.Equal(t, 1, app.outbox.Len())
+
+	require.NoError(t, client.Reconnect())
+	assert.Equal(t, 0, app.outbox.Len())
+	require.NotNil(t, expiry)
+	assert.LessOrEqual(t, *expiry, uint32(60))
+	assert.Positive(t, *expiry)
+}
+
+func TestOutboxOverflowIsReportedInHealth(t *testing.T) {
+	bus := memory.NewBus()
+	app, applicationSource := startOutboxApplication(t, bus, outbox.Config{MaxSize: 2048})
+
+	client := app.mqttClient.(*memory.Client)
+	client.SimulateConnectionLoss()
+	for i := 0; i < 5; i++ {
+		app.ResourceChanged(api.ResourceMam, applicationSource, nil)
+	}
+	assert.Equal(t, api.Health_OffSpec, applicationSource.GetHealth().Health)
+	assert.Positive(t, app.outbox.Dropped())
+
+	require.NoError(t, client.Reconnect())
+	assert.Equal(t, api.Health_Normal, applicationSource.GetHealth().Health)
+}
+
+func TestConnectionListenerAndOfflineHealth(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+	bus := memory.NewBus()
+
+	applicationSource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	app := CreateNewApplication(api.ServiceTypeUtility, applicationSource, logger.Sugar(), WithMqttClientFn(bus.NewClient), WithOfflineHealth(api.Health_CheckFunction))
+
+	states := make([]api.ConnectionState, 0)
+	app.AddConnectionListener(func(state api.ConnectionState, _ error) {
+		states = append(states, state)
+	})
+	require.NoError(t, app.Start(testStorage()))
+	assert.True(t, app.IsConnected())
+
+	client := app.mqttClient.(*memory.Client)
+	client.SimulateConnectionLoss()
+	assert.False(t, app.IsConnected())
+	assert.Equal(t, api.Health{Health: api.Health_CheckFunction, HealthScore: 100}, applicationSource.GetHealth())
+
+	require.NoError(t, client.Reconnect())
+	assert.Equal(t, api.Health{Health: api.Health_Normal, HealthScore: 100}, applicationSource.GetHealth())
+	assert.Equal(t, []api.ConnectionState{api.Connected, api.ConnectionLost, api.Reconnecting, api.Reconnected}, states)
+}
+
+func TestUnsupportedOfflineHealthIsIgnored(t *testing.T) {
+	observedZapCore, observedLogs := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+
+	applicationSource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	app := CreateNewApplication(api.ServiceTypeUtility, applicationSource, logger.Sugar(), WithOfflineHealth(api.Health_Normal))
+
+	assert.Nil(t, app.offlineHealth)
+	assert.Equal(t, 1, observedLogs.FilterLevelExact(zap.WarnLevel).Len())
+}
+
+func TestMessagePropertiesOnMemoryBus(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+	bus := memory.NewBus()
+
+	registrySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "2"})
+	registry := CreateNewApplication(api.ServiceTypeRegistry, registrySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, registry.Start(testStorage()))
+
+	var properties *api.MessageProperties
+	var correlationId *string
+	handler := subscription.NewMessageHandlerWithProperties(registry, func(_ api.ResourceType, _ *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic, p *api.MessageProperties) {
+		properties = p
+		correlationId = networkMessage.CorrelationId
+	})
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription("Oi4/Utility/+/+/+/+/Pub/#", handler)))
+
+	utilitySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	utility := CreateNewApplication(api.ServiceTypeUtility, utilitySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, utility.Start(testStorage()))
+
+	// the expiry of the publication is sent as message property
+	expiring := pub.NewBuilder(utility).Oi4Source(utilitySource).Resource(api.ResourceLicense).DataFunc(func() any {
+		return &api.License{}
+	}).PublishOnRegistration(true).MessageExpiry(time.Minute).Build()
+	require.NoError(t, utility.RegisterPublication(expiring))
+	require.NotNil(t, properties)
+	require.NotNil(t, properties.MessageExpiry)
+	assert.Equal(t, uint32(60), *properties.MessageExpiry)
+
+	// the correlation data is the CorrelationId of a NetworkMessage without one
+	require.NoError(t, utility.mqttClient.PublishResource("Oi4/Utility/acme.com///1/Pub/Health/acme.com///1", 1, false, &api.NetworkMessage{MessageId: "1"},
+		api.WithCorrelationData([]byte("42")), api.WithUserProperty("site", "plant-1")))
+	require.NotNil(t, correlationId)
+	assert.Equal(t, "42", *correlationId)
+	assert.Equal(t, map[string]string{"site": "plant-1"}, properties.UserProperties)
+}
+
+func TestSharedSubscriptionOnMemoryBus(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+	bus := memory.NewBus()
+
+	received := make(map[string][]*tp.Topic)
+	for _, serialNumber := range []string{"2", "3"} {
+		consumerSource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: serialNumber})
+		consumer := CreateNewApplication(api.ServiceTypeRegistry, consumerSource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+		require.NoError(t, consumer.Start(testStorage()))
+
+		handler := subscription.NewMessageHandler(consumer, func(_ api.ResourceType, _ *api.Oi4Identifier, _ api.NetworkMessage, topic *tp.Topic) {
+			received[serialNumber] = append(received[serialNumber], topic)
+		})
+		sharedSubscription := subscription.NewTopicSubscription("Oi4/Utility/+/+/+/+/Pub/Health/#", handler, subscription.WithSharedGroup("consumers"))
+		assert.Equal(t, "$share/consumers/Oi4/Utility/+/+/+/+/Pub/Health/#", sharedSubscription.GetTopic())
+		require.NoError(t, consumer.RegisterSubscription(sharedSubscription))
+	}
+
+	utilitySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	utility := CreateNewApplication(api.ServiceTypeUtility, utilitySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, utility.Start(testStorage()))
+
+	for i := 0; i < 4; i++ {
+		utility.ResourceChanged(api.ResourceHealth, utilitySource, nil)
+	}
+
+	require.Len(t, received["2"], 2)
+	require.Len(t, received["3"], 2)
+	assert.Equal(t, api.ServiceTypeUtility, received["2"][0].ServiceType)
+	assert.Equal(t, api.ResourceHealth, received["2"][0].Resource)
+	assert.True(t, received["2"][0].Oi4Identifier.Equals(utilitySource.GetOi4Identifier()))
+}
+
+func TestGetRequestWithPaginationAndLocale(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+	bus := memory.NewBus()
+
+	utilitySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"},
+		source.WithDataFn(func(_ api.BaseSource, filter *api.Filter) []api.Data {
+			data := make([]api.Data, 0)
+			for i := 1; i <= 5; i++ {
+				tag := fmt.Sprintf("tag%d", i)
+				if filter == nil || filter.String() == tag {
+					data = append(data, &api.SimpleData{Value: tag})
+				}
+			}
+			return data
+		}),
+		source.WithLocalizer(func(resource api.ResourceType, data any, locale string) (any, bool) {
+			if resource != api.ResourceData || locale != "de-DE" {
+				return nil, false
+			}
+			localized := make([]any, 0)
+			for _, tag := range data.([]any) {
+				localized = append(localized, fmt.Sprintf("%s (de)", tag))
+			}
+			return localized, true
+		}))
+	utility := CreateNewApplication(api.ServiceTypeUtility, utilitySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, utility.Start(testStorage()))
+	defer utility.Stop()
+	require.NoError(t, utility.RegisterPublication(pub.NewResourcePublication(utility, utilitySource, api.ResourceData)))
+
+	registrySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "2"})
+	registry := CreateNewApplication(api.ServiceTypeRegistry, registrySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, registry.Start(testStorage()))
+	defer registry.Stop()
+
+	var responses []api.NetworkMessage
+	handler := subscription.NewMessageHandler(registry, func(_ api.ResourceType, _ *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+		responses = append(responses, networkMessage)
+	})
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription("Oi4/Utility/+/+/+/+/Pub/Data/#", handler)))
+
+	getTopic := "Oi4/Utility/acme.com///1/Get/Data/acme.com///1"
+	require.NoError(t, registry.SendGetMessage(getTopic, api.GetMessage{
+		MessageId: "page-request",
+		Messages: []any{
+			api.DataSetMessage{DataSetWriterId: api.DataSetWriterIdPaginationRequest, Payload: api.PaginationRequest{PerPage: 2, Page: 2}},
+			api.DataSetMessage{DataSetWriterId: api.DataSetWriterIdLocale, Payload: api.Locale{Locale: "de-DE"}},
+		},
+	}))
+
+	require.Len(t, responses, 1)
+	response := responses[0]
+	require.NotNil(t, response.CorrelationId)
+	assert.Equal(t, "page-request", *response.CorrelationId)
+	require.Len(t, response.Messages, 3)
+	// the data of all tags is a list in a single DataSetMessage
+	assert.Equal(t, []any{"tag3 (de)", "tag4 (de)"}, response.Messages[0].Payload)
+
+	assert.Equal(t, api.DataSetWriterIdPagination, response.Messages[1].DataSetWriterId)
+	pagination := api.PaginationResponse{}
+	require.NoError(t, decodePayload(response.Messages[1].Payload, &pagination))
+	assert.NotEmpty(t, pagination.PaginationId)
+	pagination.PaginationId = ""
+	assert.Equal(t, api.PaginationResponse{TotalCount: 5, PerPage: 2, Page: 2, HasNext: true}, pagination)
+
+	assert.Equal(t, api.DataSetWriterIdLocale, response.Messages[2].DataSetWriterId)
+	locale := api.Locale{}
+	require.NoError(t, decodePayload(response.Messages[2].Payload, &locale))
+	assert.Equal(t, "de-DE", locale.Locale)
+
+	// the filter of the topic selects the data, an unsupported locale is answered in the default language
+	responses = nil
+	require.NoError(t, registry.SendGetMessage(getTopic+"/tag5", api.GetMessage{
+		MessageId: "filter-request",
+		Messages:  []any{api.DataSetMessage{DataSetWriterId: api.DataSetWriterIdLocale, Payload: api.Locale{Locale: "fr-FR"}}},
+	}))
+	require.Len(t, responses, 1)
+	require.Len(t, responses[0].Messages, 1)
+	assert.Equal(t, []any{"tag5"}, responses[0].Messages[0].Payload)
+}
+
+func TestGetCollectsPagesOfResponses(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+	bus := memory.NewBus()
+
+	utilitySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"},
+		source.WithDataFn(func(_ api.BaseSource, _ *api.Filter) []api.Data {
+			data := make([]api.Data, 0)
+			for i := 1; i <= 5; i++ {
+				data = append(data, &api.SimpleData{Value: fmt.Sprintf("tag%d", i)})
+			}
+			return data
+		}))
+	utility := CreateNewApplication(api.ServiceTypeUtility, utilitySource, logger.Sugar(), WithMqttClientFn(bus.NewClient), WithPagination(2, time.Minute))
+	require.NoError(t, utility.Start(testStorage()))
+	defer utility.Stop()
+	require.NoError(t, utility.RegisterPublication(pub.NewResourcePublication(utility, utilitySource, api.ResourceData)))
+	utility.RegisterAsset(CreateNewAsset(source.NewAssetSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "3"}), utility))
+
+	registrySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "2"})
+	registry := CreateNewApplication(api.ServiceTypeRegistry, registrySource, logger.Sugar(), WithMqttClientFn(bus.NewClient), WithGetResponseWindow(10*time.Millisecond))
+	require.NoError(t, registry.Start(testStorage()))
+	defer registry.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	target := utility.GetOi4Identifier()
+
+	// the pages are requested one after the other
+	pages, err := GetAs[[]string](ctx, registry, api.ServiceTypeUtility, target, api.ResourceData, &target, nil)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"tag1", "tag2"}, {"tag3", "tag4"}, {"tag5"}}, pages)
+	assert.Equal(t, 0, utility.paginator.Len())
+
+	// a subscription of the registry with the response filter keeps receiving the publications
+	published := 0
+	mamFilter := tp.NewFilter().ServiceType(api.ServiceTypeUtility).Oi4Identifier(&target).Method(api.MethodPub).Resource(api.ResourceMam).String()
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription(mamFilter, subscription.NewMessageHandler(registry, func(api.ResourceType, *api.Oi4Identifier, api.NetworkMessage, *tp.Topic) {
+		published++
+	}))))
+	published = 0
+
+	// a request without source is answered by the application and its assets
+	mams, err := GetAs[api.MasterAssetModel](ctx, registry, api.ServiceTypeUtility, target, api.ResourceMam, nil, nil)
+	require.NoError(t, err)
+	serialNumbers := make([]string, 0)
+	for _, mam := range mams {
+		serialNumbers = append(serialNumbers, mam.SerialNumber)
+	}
+	assert.ElementsMatch(t, []string{"1", "3"}, serialNumbers)
+	assert.Empty(t, registry.getCalls)
+	// only the handler of the subscription is left
+	require.Len(t, registry.filterHandlers, 1)
+	assert.Equal(t, int32(0), registry.filterHandlers[mamFilter].gets.Load())
+	// the subscription receives the responses, it is not subscribed again
+	assert.Equal(t, 2, published)
+
+	utility.ResourceChanged(api.ResourceMam, utilitySource, nil)
+	assert.Equal(t, 3, published)
+
+	// a request without response ends with the context
+	unknown := api.Oi4Identifier{ManufacturerUri: "acme.com", SerialNumber: "4"}
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelTimeout()
+	_, err = registry.Get(timeout, api.ServiceTypeUtility, unknown, api.ResourceHealth, &unknown, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = registry.Get(ctx, api.ServiceTypeUtility, target, api.ResourceBlink, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestGetResponsesSurviveSubscriptionChanges(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+	bus := memory.NewBus()
+
+	utilitySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	utility := CreateNewApplication(api.ServiceTypeUtility, utilitySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, utility.Start(testStorage()))
+	defer utility.Stop()
+
+	registrySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "2"})
+	registry := CreateNewApplication(api.ServiceTypeRegistry, registrySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, registry.Start(testStorage()))
+	defer registry.Stop()
+
+	// a Get request waits for responses
+	mamFilter := tp.NewFilter().ServiceType(api.ServiceTypeUtility).Oi4Identifier(utility.oi4Identifier).Method(api.MethodPub).Resource(api.ResourceMam).String()
+	require.NoError(t, registry.subscribeGetResponses(mamFilter))
+	call := newGetCall()
+	registry.getMutex.Lock()
+	registry.getCalls["get"] = call
+	registry.getMutex.Unlock()
+	correlationId := "get"
+	respond := func() {
+		utility.triggerSourcePublication(utilitySource, api.ResourceMam, nil, api.OnRequest, &correlationId)
+	}
+
+	// a subscription of the filter registered meanwhile receives the messages, the request its responses
+	published := 0
+	mamSubscription := subscription.NewTopicSubscription(mamFilter, subscription.NewMessageHandler(registry, func(api.ResourceType, *api.Oi4Identifier, api.NetworkMessage, *tp.Topic) {
+		published++
+	}))
+	require.NoError(t, registry.RegisterSubscription(mamSubscription))
+	published = 0
+	respond()
+	assert.Len(t, call.take(), 1)
+	assert.Equal(t, 1, published)
+
+	// removing the subscription keeps the responses
+	require.NoError(t, registry.RemoveSubscription(mamSubscription))
+	respond()
+	assert.Len(t, call.take(), 1)
+	assert.Equal(t, 1, published)
+
+	registry.unsubscribeGetResponses(mamFilter)
+	assert.Empty(t, registry.filterHandlers)
+	respond()
+	assert.Empty(t, call.take())
+}
+
+func TestSetConfigIsValidatedPersistedAndPublished(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+	bus := memory.NewBus()
+	storage := testStorage()
+	storage.ApplicationSpecificStorages = &container.ApplicationSpecificStorages{ConfigurationPath: t.TempDir()}
+
+	maxInterval := 60.0
+	config := api.PublishConfig{Groups: map[string]api.ConfigGroup{
+		"Polling": {Parameters: map[string]api.ConfigParameter{
+			"Interval": {Type: api.ConfigParameterNumber, Value: 10.0, Validation: &api.ConfigValidation{Max: &maxInterval}},
+		}},
+	}}
+	// the asset is registered before the start
+	newUtility := func(applied *[]api.PublishConfig) *Oi4ApplicationImpl {
+		utilitySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"}, source.WithConfig(config))
+		utility := CreateNewApplication(api.ServiceTypeUtility, utilitySource, logger.Sugar(), WithMqttClientFn(bus.NewClient),
+			WithApplyConfigFn(func(_ api.BaseSource, config api.PublishConfig) error {
+				if config.Groups["Polling"].Parameters["Interval"].Value == 0.0 {
+					return fmt.Errorf("polling can not be disabled")
+				}
+				*applied = append(*applied, config)
+				return nil
+			}))
+		assetSource := source.NewAssetSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "3"}, source.WithConfig(config))
+		utility.RegisterAsset(CreateNewAsset(assetSource, utility))
+		return utility
+	}
+
+	var applied []api.PublishConfig
+	utility := newUtility(&applied)
+	require.NoError(t, utility.Start(storage))
+
+	registrySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "2"})
+	registry := CreateNewApplication(api.ServiceTypeRegistry, registrySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, registry.Start(testStorage()))
+	defer registry.Stop()
+
+	var responses []api.NetworkMessage
+	handler := subscription.NewMessageHandler(registry, func(_ api.ResourceType, _ *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+		responses = append(responses, networkMessage)
+	})
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription("Oi4/Utility/+/+/+/+/Pub/Config/#", handler)))
+
+	set := func(target string, messageId string, interval any) api.NetworkMessage {
+		responses = nil
+		require.NoError(t, registry.mqttClient.PublishResource("Oi4/Utility/acme.com///1/Set/Config/"+target, 1, false, api.NetworkMessage{
+			MessageId: messageId,
+			Messages:  []*api.DataSetMessage{{Payload: api.SetConfig{"Polling": {"Interval": interval}}}},
+		}))
+		require.Len(t, responses, 1)
+		require.NotNil(t, responses[0].CorrelationId)
+		assert.Equal(t, messageId, *responses[0].CorrelationId)
+		return responses[0]
+	}
+	interval := func(message *api.DataSetMessage) any {
+		published := api.PublishConfig{}
+		require.NoError(t, decodePayload(message.Payload, &published))
+		return published.Groups["Polling"].Parameters["Interval"].Value
+	}
+
+	accepted := set("acme.com///1", "accepted", 30)
+	assert.Nil(t, accepted.Messages[0].Status)
+	assert.Equal(t, 30.0, interval(accepted.Messages[0]))
+	require.Len(t, applied, 1)
+	assert.FileExists(t, utility.configFile(utility.applicationSource))
+
+	invalid := set("acme.com///1", "invalid", 61)
+	require.NotNil(t, invalid.Messages[0].Status)
+	assert.Equal(t, api.Status_BadInvalidArgument, *invalid.Messages[0].Status)
+	assert.Equal(t, 30.0, interval(invalid.Messages[0]))
+
+	refused := set("acme.com///1", "refused", 0)
+	require.NotNil(t, refused.Messages[0].Status)
+	assert.Equal(t, api.Status_BadConfigurationError, *refused.Messages[0].Status)
+	assert.Len(t, applied, 1)
+	// the config written for the refused request is discarded
+	files, err := os.ReadDir(utility.configPath)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	assert.Nil(t, set("acme.com///3", "asset", 20).Messages[0].Status)
+
+	// the persisted config is applied after a restart
+	utility.Stop()
+	applied = nil
+	restarted := newUtility(&applied)
+	require.NoError(t, restarted.Start(storage))
+	defer restarted.Stop()
+	require.Len(t, applied, 2)
+	assert.Equal(t, 30.0, restarted.applicationSource.GetConfig().Groups["Polling"].Parameters["Interval"].Value)
+	assetSource := restarted.getSource(&api.Oi4Identifier{ManufacturerUri: "acme.com", SerialNumber: "3"})
+	assert.Equal(t, 20.0, assetSource.GetConfig().Groups["Polling"].Parameters["Interval"].Value)
+
+	// a config which can not be persisted is rejected
+	restarted.configPath = filepath.Join(t.TempDir(), "missing")
+	unpersisted := set("acme.com///1", "unpersisted", 40)
+	require.NotNil(t, unpersisted.Messages[0].Status)
+	assert.Equal(t, api.Status_BadConfigurationError, *unpersisted.Messages[0].Status)
+	assert.Equal(t, 30.0, interval(unpersisted.Messages[0]))
+	assert.Equal(t, 30.0, restarted.applicationSource.GetConfig().Groups["Polling"].Parameters["Interval"].Value)
+	assert.Len(t, applied, 2)
+}
+
+func TestDelRemovesPublicationsAndSubscriptions(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+	bus := memory.NewBus()
+
+	utilitySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	utility := CreateNewApplication(api.ServiceTypeUtility, utilitySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, utility.Start(testStorage()))
+	defer utility.Stop()
+
+	assetSource := source.NewAssetSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "3"})
+	asset := CreateNewAsset(assetSource, utility)
+	require.NoError(t, asset.RegisterPublication(pub.NewResourcePublication(utility, assetSource, api.ResourceData)))
+	utility.RegisterAsset(asset)
+
+	healthMessages := 0
+	healthTopic := "Oi4/+/+/+/+/+/Pub/Health/#"
+	require.NoError(t, utility.RegisterSubscription(subscription.NewTopicSubscription(healthTopic, subscription.NewMessageHandler(utility, func(api.ResourceType, *api.Oi4Identifier, api.NetworkMessage, *tp.Topic) {
+		healthMessages++
+	}))))
+
+	registrySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "2"})
+	registry := CreateNewApplication(api.ServiceTypeRegistry, registrySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, registry.Start(testStorage()))
+	defer registry.Stop()
+
+	responses := make(map[api.ResourceType]api.NetworkMessage)
+	handler := subscription.NewMessageHandler(registry, func(resource api.ResourceType, _ *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+		responses[resource] = networkMessage
+	})
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription("Oi4/Utility/+/+/+/+/Pub/+/#", handler)))
+
+	// the Data publication of the asset is removed
+	require.NoError(t, registry.mqttClient.PublishResource("Oi4/Utility/acme.com///1/Del/PublicationList/acme.com///3", 1, false, api.NetworkMessage{
+		MessageId: "del-publication",
+		Messages:  []*api.DataSetMessage{{Payload: []api.PublicationList{{ResourceType: api.ResourceData}}}},
+	}))
+	assert.Empty(t, asset.publications[api.ResourceData])
+	response := responses[api.ResourcePublicationList]
+	require.NotNil(t, response.CorrelationId)
+	assert.Equal(t, "del-publication", *response.CorrelationId)
+	publicationList := make([]api.PublicationList, 0)
+	require.NoError(t, decodePayload(response.Messages[0].Payload, &publicationList))
+	assert.NotEmpty(t, publicationList)
+	for _, entry := range publicationList {
+		assert.NotEqual(t, api.ResourceData, entry.ResourceType)
+	}
+
+	// the filter of the topic names the publication
+	require.NoError(t, asset.RegisterPublication(pub.NewResourcePublicationWithFilter(utility, assetSource, api.ResourceData, api.NewFilter("tag1"))))
+	require.NoError(t, registry.mqttClient.PublishResource("Oi4/Utility/acme.com///1/Del/PublicationList/acme.com///3/tag1", 1, false, api.NetworkMessage{MessageId: "del-filter"}))
+	assert.Empty(t, asset.publications[api.ResourceData])
+	response = responses[api.ResourcePublicationList]
+	assert.Equal(t, "del-filter", *response.CorrelationId)
+	assert.Nil(t, response.Messages[0].Status)
+
+	// a request matching no publication is rejected
+	require.NoError(t, registry.mqttClient.PublishResource("Oi4/Utility/acme.com///1/Del/PublicationList/acme.com///3/tag2", 1, false, api.NetworkMessage{MessageId: "del-unknown"}))
+	response = responses[api.ResourcePublicationList]
+	assert.Equal(t, "del-unknown", *response.CorrelationId)
+	require.NotNil(t, response.Messages[0].Status)
+	assert.Equal(t, api.Status_BadNotFound, *response.Messages[0].Status)
+
+	// the subscription of the application is unsubscribed
+	subscriptionList := make([]api.SubscriptionList, 0)
+	require.NoError(t, decodePayload(utilitySource.Get(api.ResourceSubscriptionList, nil)[0], &subscriptionList))
+	assert.Equal(t, []api.SubscriptionList{{TopicPath: healthTopic, Config: api.SubsciptionConfig_NONE_0}}, subscriptionList)
+
+	// a request for the asset keeps the subscriptions of the application
+	require.NoError(t, registry.mqttClient.PublishResource("Oi4/Utility/acme.com///1/Del/SubscriptionList/acme.com///3", 1, false, api.NetworkMessage{
+		MessageId: "del-asset-subscription",
+		Messages:  []*api.DataSetMessage{{Payload: api.SubscriptionList{TopicPath: healthTopic}}},
+	}))
+	assert.Len(t, utility.GetSubscriptions(), 1)
+
+	// the filter of the topic is the TopicPath of the subscription
+	require.NoError(t, registry.mqttClient.PublishResource("Oi4/Utility/acme.com///1/Del/SubscriptionList/acme.com///1/"+dnp.Encode(healthTopic), 1, false, api.NetworkMessage{
+		MessageId: "del-subscription",
+	}))
+	assert.Empty(t, utility.GetSubscriptions())
+	response = responses[api.ResourceSubscriptionList]
+	require.NotNil(t, response.CorrelationId)
+	assert.Equal(t, "del-subscription", *response.CorrelationId)
+
+	received := healthMessages
+	registrySource.UpdateHealth(api.Health{Health: api.Health_Normal, HealthScore: 50})
+	assert.Equal(t, received, healthMessages)
+}
+
+func TestCallDispatchesRegisteredMethods(t *testing.T) {
+	utility, _, call := startCallTarget(t, memory.NewBus(), api.ServiceTypeUtility, testStorage())
+
+	var blinked []string
+	blink := api.Method{
+		InputArguments: []api.MethodArgument{api.NewArgument[int]("Duration"), api.NewOptionalArgument[string]("Pattern")},
+		Handler: func(source api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+			blinked = append(blinked, source.GetOi4Identifier().SerialNumber)
+			return []any{inputArguments[0].(int) * 2}, api.Status_Good
+		},
+	}
+	require.NoError(t, utility.RegisterMethod(api.ResourceBlink, "Blink", blink))
+	assert.Error(t, utility.RegisterMethod(api.ResourceMam, "Blink", blink))
+
+	asset := CreateNewAsset(source.NewAssetSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "3"}), utility)
+	require.NoError(t, asset.RegisterMethod(api.ResourceBlink, "Blink", blink))
+	utility.RegisterAsset(asset)
+
+	assert.Equal(t, api.CallMethodResult{StatusCode: api.Status_Good, InputArgumentResults: []api.StatusCode{api.Status_Good}, OutputArguments: []any{6.0}},
+		call(api.ResourceBlink, "acme.com///1", "Blink", 3))
+	invalid := call(api.ResourceBlink, "acme.com///1", "Blink", "long")
+	assert.Equal(t, api.Status_BadInvalidArgument, invalid.StatusCode)
+	assert.Equal(t, []api.StatusCode{api.Status_BadTypeMismatch}, invalid.InputArgumentResults)
+	assert.Equal(t, api.Status_BadArgumentsMissing, call(api.ResourceBlink, "acme.com///1", "Blink").StatusCode)
+	assert.Equal(t, api.Status_BadTooManyArguments, call(api.ResourceBlink, "acme.com///1", "Blink", 1, "fast", true).StatusCode)
+	assert.Equal(t, api.Status_BadMethodInvalid, call(api.ResourceBlink, "acme.com///1", "Unknown").StatusCode)
+
+	assert.Equal(t, api.Status_Good, call(api.ResourceBlink, "acme.com///3", "Blink", 1, "fast").StatusCode)
+	assert.Equal(t, []string{"1", "3"}, blinked)
+}
+
+// callFunc sends a Call request for a method of a service of the source and returns the result of the Reply
+type callFunc func(service api.ResourceType, source string, methodId string, inputArguments ...any) api.CallMethodResult
+
+// startCallTarget starts an application with the options and a registry calling the methods of the application and
+// its assets. Both applications are stopped with the end of the test.
+func startCallTarget(t *testing.T, bus *memory.Bus, serviceType api.ServiceType, storage container.Storage, options ...Option) (*Oi4ApplicationImpl, *Oi4ApplicationImpl, callFunc) {
+	observedZapCore, _ := observer.New(zap.DebugLevel)
+	logger := zap.New(observedZapCore)
+
+	targetSource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "1"})
+	target := CreateNewApplication(serviceType, targetSource, logger.Sugar(), append([]Option{WithMqttClientFn(bus.NewClient)}, options...)...)
+	require.NoError(t, target.Start(storage))
+	t.Cleanup(target.Stop)
+
+	registrySource := source.NewApplicationSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "2"})
+	registry := CreateNewApplication(api.ServiceTypeRegistry, registrySource, logger.Sugar(), WithMqttClientFn(bus.NewClient))
+	require.NoError(t, registry.Start(testStorage()))
+	t.Cleanup(registry.Stop)
+
+	var results []api.CallMethodResult
+	replies := subscription.NewServiceMessageHandler(registry, func(_ api.ResourceType, _ *api.Oi4Identifier, serviceMessage api.ServiceNetworkMessage, _ *tp.Topic) {
+		response := api.ServiceParametersResponse{}
+		require.NoError(t, decodePayload(serviceMessage.Message, &response))
+		results = append(results, response.Results...)
+	})
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription(fmt.Sprintf("Oi4/%s/+/+/+/+/Reply/#", serviceType), replies)))
+
+	call := func(service api.ResourceType, source string, methodId string, inputArguments ...any) api.CallMethodResult {
+		results = nil
+		topic := fmt.Sprintf("Oi4/%s/acme.com///1/Call/%s/%s", serviceType, service, source)
+		require.NoError(t, registry.mqttClient.PublishResource(topic, 1, false, api.ServiceNetworkMessage{
+			MessageId: methodId,
+			Message:   api.ServiceParameterRequest{MethodsToCall: []api.CallMethodRequest{{MethodId: methodId, InputArguments: inputArguments}}},
+		}))
+		require.Len(t, results, 1)
+		return results[0]
+	}
+	return target, registry, call
+}
+
+func TestFileTransferOverCall(t *testing.T) {
+	storage := testStorage()
+	storage.ApplicationSpecificStorages = &container.ApplicationSpecificStorages{DataPath: t.TempDir()}
+	_, _, call := startCallTarget(t, memory.NewBus(), api.ServiceTypeUtility, storage,
+		WithFileTransfer(func(api.BaseSource, string, filetransfer.Access) bool { return true }))
+
+	content := []byte("recipe")
+	assert.Equal(t, api.Status_Good, call(api.ResourceFileUpload, "acme.com///1", filetransfer.MethodWrite, "recipe.txt", 0, content, filetransfer.Checksum(content)).StatusCode)
+	assert.Equal(t, api.Status_Good, call(api.ResourceFileUpload, "acme.com///1", filetransfer.MethodCommit, "recipe.txt", filetransfer.Checksum(content)).StatusCode)
+	assert.FileExists(t, filepath.Join(storage.ApplicationSpecificStorages.DataPath, fileTransferDirectory, "recipe.txt"))
+
+	read := call(api.ResourceFileDownload, "acme.com///1", filetransfer.MethodRead, "recipe.txt", 0)
+	require.Equal(t, api.Status_Good, read.StatusCode)
+	data := make([]byte, 0)
+	require.NoError(t, decodePayload(read.OutputArguments[0], &data))
+	assert.Equal(t, content, data)
+}
+
+type firmwareDriverMock struct {
+	applied string
+}
+
+func (d *firmwareDriverMock) Download(context.Context, string) ([]byte, error) {
+	return nil, errors.New("images are uploaded")
+}
+
+func (d *firmwareDriverMock) Apply(_ context.Context, _ []byte, version string) error {
+	d.applied = version
+	return nil
+}
+
+func (d *firmwareDriverMock) Reboot(context.Context) error {
+	return nil
+}
+
+func TestFirmwareUpdateOfAsset(t *testing.T) {
+	bus := memory.NewBus()
+	storage := testStorage()
+	storage.ApplicationSpecificStorages = &container.ApplicationSpecificStorages{DataPath: t.TempDir()}
+	utility, registry, call := startCallTarget(t, bus, api.ServiceTypeUtility, storage,
+		WithFileTransfer(func(api.BaseSource, string, filetransfer.Access) bool { return true }))
+
+	assetSource := source.NewAssetSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "3", SoftwareRevision: "1.0.0"})
+	asset := CreateNewAsset(assetSource, utility)
+	driver := &firmwareDriverMock{}
+	updater, err := asset.RegisterFirmwareUpdate(driver)
+	require.NoError(t, err)
+	utility.RegisterAsset(asset)
+
+	var events []api.Event
+	eventMutex := sync.Mutex{}
+	eventHandler := subscription.NewMessageHandler(registry, func(_ api.ResourceType, _ *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+		eventMutex.Lock()
+		defer eventMutex.Unlock()
+		for _, message := range networkMessage.Messages {
+			event := api.Event{}
+			require.NoError(t, decodePayload(message.Payload, &event))
+			events = append(events, event)
+		}
+	})
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription("Oi4/Utility/acme.com///1/Pub/Event/acme.com///3/#", eventHandler)))
+
+	image := []byte("firmware 2.0.0")
+	checksum := filetransfer.Checksum(image)
+	require.Equal(t, api.Status_Good, call(api.ResourceFileUpload, "acme.com///1", filetransfer.MethodWrite, "firmware.bin", 0, image, checksum).StatusCode)
+	require.Equal(t, api.Status_Good, call(api.ResourceFileUpload, "acme.com///1", filetransfer.MethodCommit, "firmware.bin", checksum).StatusCode)
+
+	assert.Equal(t, api.Status_Good, call(api.ResourceFirmwareUpdate, "acme.com///3", firmware.MethodUpdate, "2.0.0", checksum, "firmware.bin").StatusCode)
+	updater.Wait()
+
+	assert.Equal(t, "2.0.0", driver.applied)
+	assert.Equal(t, "2.0.0", assetSource.GetMasterAssetModel().SoftwareRevision)
+	assert.Equal(t, int32(1), assetSource.GetMasterAssetModel().RevisionCounter)
+
+	eventMutex.Lock()
+	defer eventMutex.Unlock()
+	require.Len(t, events, 5)
+	assert.Equal(t, api.EventCategoryGENERIC, events[4].Category)
+	assert.Equal(t, "Firmware update Done", events[4].Description)
+}
+
+type blinkingAssetSource struct {
+	*source.AssetSourceImpl
+	blinked chan time.Duration
+}
+
+func (s *blinkingAssetSource) Blink(_ context.Context, duration time.Duration) error {
+	s.blinked <- duration
+	return nil
+}
+
+func TestBlinkOfAssets(t *testing.T) {
+	utility, _, call := startCallTarget(t, memory.NewBus(), api.ServiceTypeUtility, testStorage())
+
+	blinking := &blinkingAssetSource{
+		AssetSourceImpl: source.NewAssetSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "3"}),
+		blinked:         make(chan time.Duration, 1),
+	}
+	utility.RegisterAsset(CreateNewAsset(blinking, utility))
+	utility.RegisterAsset(CreateNewAsset(source.NewAssetSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "4"}), utility))
+
+	assert.Equal(t, api.Status_Good, call(api.ResourceBlink, "acme.com///3", blink.MethodBlink, 2).StatusCode)
+	assert.Equal(t, 2*time.Second, <-blinking.blinked)
+	assert.Equal(t, api.Status_BadServiceUnsupported, call(api.ResourceBlink, "acme.com///4", blink.MethodBlink, 2).StatusCode)
+}
+
+func TestNewDataSetWriterIdReassignsPublications(t *testing.T) {
+	utility, registry, callService := startCallTarget(t, memory.NewBus(), api.ServiceTypeUtility, testStorage())
+
+	var publicationLists, healths []*api.DataSetMessage
+	published := subscription.NewMessageHandler(registry, func(resource api.ResourceType, _ *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+		if resource == api.ResourcePublicationList {
+			publicationLists = networkMessage.Messages
+		} else {
+			healths = networkMessage.Messages
+		}
+	})
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription("Oi4/Utility/acme.com///1/Pub/PublicationList/#", published)))
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription("Oi4/Utility/acme.com///1/Pub/Health/#", published)))
+
+	call := func(methodId string, inputArguments ...any) api.CallMethodResult {
+		return callService(api.ResourceNewDataSetWriterId, "acme.com///1", methodId, inputArguments...)
+	}
+
+	health := utility.GetDataSetWriterId(api.ResourceHealth, utility.oi4Identifier, nil)
+	mam := utility.GetDataSetWriterId(api.ResourceMam, utility.oi4Identifier, nil)
+	current := call(MethodGetDataSetWriterId, api.ResourceHealth)
+	require.Equal(t, api.Status_Good, current.StatusCode)
+	assert.Equal(t, []any{float64(health)}, current.OutputArguments)
+
+	assert.Equal(t, api.Status_BadEntryExists, call(MethodAssignDataSetWriterId, api.ResourceHealth, "acme.com///1", nil, mam).StatusCode)
+	assert.Equal(t, api.Status_BadInvalidArgument, call(MethodAssignDataSetWriterId, api.ResourceHealth, nil, nil, api.DataSetWriterIdLocale).StatusCode)
+	assert.Equal(t, api.Status_BadNotFound, call(MethodAssignDataSetWriterId, api.ResourceHealth, "acme.com///9").StatusCode)
+
+	assigned := call(MethodAssignDataSetWriterId, api.ResourceHealth, "acme.com///1", nil, 500)
+	require.Equal(t, api.Status_Good, assigned.StatusCode)
+	assert.Equal(t, []any{float64(500)}, assigned.OutputArguments)
+
+	require.Len(t, publicationLists, 1)
+	entries := make([]api.PublicationList, 0)
+	require.NoError(t, decodePayload(publicationLists[0].Payload, &entries))
+	ids := make(map[api.ResourceType]uint16)
+	for _, entry := range entries {
+		ids[entry.ResourceType] = entry.DataSetWriterId
+	}
+	assert.Equal(t, uint16(500), ids[api.ResourceHealth])
+	assert.Equal(t, mam, ids[api.ResourceMam])
+
+	utility.UpdateHealth(api.Health{Health: api.Health_Normal, HealthScore: 90})
+	require.Len(t, healths, 1)
+	assert.Equal(t, uint16(500), healths[0].DataSetWriterId)
+
+	// the ids of the applications in the same process are independent
+	assert.Equal(t, health, registry.GetDataSetWriterId(api.ResourceHealth, registry.oi4Identifier, nil))
+}
+
+type connectorAssetSource struct {
+	*source.AssetSourceImpl
+	speed atomic.Int32
+}
+
+func (s *connectorAssetSource) GetVariables() []api.FieldMetaData {
+	return []api.FieldMetaData{{Name: "Speed", BuiltInType: api.BuiltInType_Int32, ValueRank: api.ValueRankScalar}}
+}
+
+func (s *connectorAssetSource) ReadVariable(string) (any, api.StatusCode) {
+	return s.speed.Load(), api.Status_Good
+}
+
+func (s *connectorAssetSource) WriteVariable(_ string, value any) api.StatusCode {
+	s.speed.Store(value.(int32))
+	return api.Status_Good
+}
+
+func (s *connectorAssetSource) CallGenericMethod(string, []any) ([]any, api.StatusCode) {
+	return nil, api.Status_BadMethodInvalid
+}
+
+func TestSpecificServicesOfConnectorAsset(t *testing.T) {
+	connector, registry, callService := startCallTarget(t, memory.NewBus(), api.ServiceTypeOTConnector, testStorage())
+
+	device := &connectorAssetSource{AssetSourceImpl: source.NewAssetSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "3"})}
+	connector.RegisterAsset(CreateNewAsset(device, connector))
+
+	var values []any
+	valuesMutex := sync.Mutex{}
+	data := subscription.NewMessageHandler(registry, func(_ api.ResourceType, _ *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+		valuesMutex.Lock()
+		defer valuesMutex.Unlock()
+		for _, message := range networkMessage.Messages {
+			values = append(values, message.Payload)
+		}
+	})
+	require.NoError(t, registry.RegisterSubscription(subscription.NewTopicSubscription("Oi4/OTConnector/acme.com///1/Pub/Data/acme.com///3/Speed", data)))
+
+	call := func(service api.ResourceType, methodId string, inputArguments ...any) api.CallMethodResult {
+		return callService(service, "acme.com///3", methodId, inputArguments...)
+	}
+
+	assert.Equal(t, api.Status_BadTypeMismatch, call(api.ResourceWrite, specific.MethodWrite, "Speed", "fast").StatusCode)
+	assert.Equal(t, api.Status_Good, call(api.ResourceWrite, specific.MethodWrite, "Speed", 1500).StatusCode)
+	assert.Equal(t, []any{1500.0}, call(api.ResourceRead, specific.MethodRead, "Speed").OutputArguments)
+
+	subscribed := call(api.ResourceSubscribe, specific.MethodSubscribe, "Speed", 100)
+	require.Equal(t, api.Status_Good, subscribed.StatusCode)
+	assert.Eventually(t, func() bool {
+		valuesMutex.Lock()
+		defer valuesMutex.Unlock()
+		return len(values) > 0
+	}, 2*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, api.Status_Good, call(api.ResourceUnsubscribe, specific.MethodUnsubscribe, "Speed").StatusCode)
+	valuesMutex.Lock()
+	assert.Equal(t, map[string]any{"Pv": 1500.0}, values[0])
+	valuesMutex.Unlock()
+}
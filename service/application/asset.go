@@ -1,8 +1,11 @@
 package application
 
 import (
+	"errors"
 	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/blink"
 	pub "github.com/OI4/oi4-oec-service-go/service/application/publication"
+	"github.com/OI4/oi4-oec-service-go/service/application/specific"
 	"maps"
 	"slices"
 	"sync"
@@ -16,6 +19,9 @@ type AssetImpl struct {
 	publicationMutex sync.RWMutex
 
 	source api.AssetSource
+
+	// methods of the asset called by Call requests
+	methods *methodRegistry
 }
 
 func CreateNewAsset(source api.AssetSource, app *Oi4ApplicationImpl) *AssetImpl {
@@ -26,10 +32,22 @@ func CreateNewAsset(source api.AssetSource, app *Oi4ApplicationImpl) *AssetImpl
 		publications:     make(map[api.ResourceType][]api.Publication),
 		publicationMutex: sync.RWMutex{},
 		source:           source,
+		methods:          newMethodRegistry(),
 	}
 
 	source.SetAsset(asset)
 
+	if driver, ok := source.(blink.Driver); ok {
+		if err := asset.RegisterBlink(driver); err != nil {
+			return nil
+		}
+	}
+	if access, ok := source.(api.VariableAccess); ok {
+		if _, err := asset.RegisterVariableAccess(access); err != nil {
+			return nil
+		}
+	}
+
 	err := asset.RegisterPublication(pub.NewHealthPublication(app, source))
 
 	if err != nil {
@@ -48,12 +66,24 @@ func CreateNewAsset(source api.AssetSource, app *Oi4ApplicationImpl) *AssetImpl
 		return nil
 	}
 
+	err = asset.RegisterPublication(pub.NewResourcePublication(app, source, api.ResourceConfig))
+
+	if err != nil {
+		return nil
+	}
+
 	err = asset.RegisterPublication(pub.NewResourcePublication(app, source, api.ResourcePublicationList))
 
 	if err != nil {
 		return nil
 	}
 
+	err = asset.RegisterPublication(pub.NewResourcePublication(app, source, api.ResourceSubscriptionList))
+
+	if err != nil {
+		return nil
+	}
+
 	err = asset.RegisterPublication(pub.NewBuilder(app). //
 								Oi4Source(source).                                  //
 								Resource(api.ResourceProfile).                      //
@@ -98,6 +128,14 @@ func (asset *AssetImpl) RegisterPublication(publication api.Publication) error {
 	return nil
 }
 
+// RemovePublication stops a publication and removes it from the asset
+func (asset *AssetImpl) RemovePublication(publication api.Publication) {
+	asset.publicationMutex.Lock()
+	defer asset.publicationMutex.Unlock()
+
+	removePublication(asset.publications, publication)
+}
+
 // GetPublications Return all registered publications
 func (asset *AssetImpl) GetPublications() []api.Publication {
 	asset.publicationMutex.RLock()
@@ -112,6 +150,31 @@ func (asset *AssetImpl) GetPublications() []api.Publication {
 	return result
 }
 
+// RegisterBlink registers the Blink service of the asset. It is registered by CreateNewAsset if the source of the
+// asset implements blink.Driver.
+func (asset *AssetImpl) RegisterBlink(driver blink.Driver) error {
+	return blink.New(driver, func(err error) {
+		if asset.parent != nil {
+			asset.parent.logger.Warnf("Failed to blink asset %s: %v", asset.source.GetOi4Identifier().ToString(), err)
+		}
+	}).Register(asset)
+}
+
+// RegisterVariableAccess registers the specific services Read, Write, Subscribe, Unsubscribe and GenericMethod for the
+// variables of the asset. They are registered by CreateNewAsset if the source of the asset implements
+// api.VariableAccess.
+func (asset *AssetImpl) RegisterVariableAccess(access api.VariableAccess) (*specific.Service, error) {
+	if asset.parent == nil {
+		return nil, errors.New("the variables of an asset require its application")
+	}
+
+	service := specific.New(asset.parent, asset.source, access)
+	if err := service.Register(asset); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
 func (asset *AssetImpl) UpdateHealth(health api.Health) {
 	asset.source.UpdateHealth(health)
 }
@@ -0,0 +1,106 @@
+// Package blink provides the Blink common service, which lets a technician identify a physical asset, e.g. by a
+// flashing LED.
+//
+// Blink:
+//   - Blink(Duration) starts blinking for Duration seconds. The reply is sent immediately, a request while the asset
+//     is still blinking is rejected with BadInvalidState.
+package blink
+
+import (
+	"context"
+	"errors"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"sync"
+	"time"
+)
+
+const (
+	MethodBlink = "Blink"
+
+	// DefaultDuration is used if the request contains no duration
+	DefaultDuration = 10 * time.Second
+	// MaxDuration limits the duration of a request
+	MaxDuration = 10 * time.Minute
+)
+
+// Driver blinks the physical asset, Blink returns once the duration is over or the context is cancelled. An asset
+// source implementing Driver registers the Blink service with its asset.
+type Driver interface {
+	Blink(ctx context.Context, duration time.Duration) error
+}
+
+type Service struct {
+	driver Driver
+	logFn  func(err error)
+
+	blinking bool
+	mutex    sync.Mutex
+	done     chan struct{}
+}
+
+// New creates the service for the driver, logFn is called with the errors of the driver and may be nil
+func New(driver Driver, logFn func(err error)) *Service {
+	return &Service{driver: driver, logFn: logFn}
+}
+
+// Register registers the method of the service
+func (s *Service) Register(registry api.MethodRegistry) error {
+	return registry.RegisterMethod(api.ResourceBlink, MethodBlink, api.Method{
+		InputArguments: []api.MethodArgument{api.NewOptionalArgument[uint32]("Duration")},
+		Handler:        s.blink,
+	})
+}
+
+// Start blinks in the background, it returns false if the asset is already blinking
+func (s *Service) Start(duration time.Duration) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.blinking {
+		return false
+	}
+
+	s.blinking = true
+	s.done = make(chan struct{})
+	go s.run(duration, s.done)
+	return true
+}
+
+// Wait blocks until the asset stopped blinking
+func (s *Service) Wait() {
+	s.mutex.Lock()
+	done := s.done
+	s.mutex.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+func (s *Service) blink(_ api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	duration := DefaultDuration
+	if seconds, ok := inputArguments[0].(uint32); ok && seconds > 0 {
+		duration = time.Duration(seconds) * time.Second
+	}
+	if duration > MaxDuration {
+		return nil, api.Status_BadOutOfRange
+	}
+
+	if !s.Start(duration) {
+		return nil, api.Status_BadInvalidState
+	}
+	return nil, api.Status_Good
+}
+
+func (s *Service) run(duration time.Duration, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	err := s.driver.Blink(ctx, duration)
+	cancel()
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && s.logFn != nil {
+		s.logFn(err)
+	}
+
+	s.mutex.Lock()
+	s.blinking = false
+	s.mutex.Unlock()
+	close(done)
+}
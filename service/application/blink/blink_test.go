@@ -0,0 +1,79 @@
+package blink
+
+import (
+	"context"
+	"errors"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/internal/methodtest"
+	"github.com/OI4/oi4-oec-service-go/service/application/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type driverMock struct {
+	durations []time.Duration
+	release   chan struct{}
+}
+
+func (d *driverMock) Blink(ctx context.Context, duration time.Duration) error {
+	d.durations = append(d.durations, duration)
+	select {
+	case <-d.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestBlinkRejectsConcurrentRequests(t *testing.T) {
+	driver := &driverMock{release: make(chan struct{})}
+	service := New(driver, nil)
+	methods := make(methodtest.Registry)
+	require.NoError(t, service.Register(methods))
+	blink := methods.Get(api.ResourceBlink, MethodBlink).Handler
+	assetSource := source.NewAssetSourceImpl(api.MasterAssetModel{})
+
+	_, status := blink(assetSource, []any{uint32(5)})
+	require.Equal(t, api.Status_Good, status)
+	_, status = blink(assetSource, []any{uint32(5)})
+	assert.Equal(t, api.Status_BadInvalidState, status)
+
+	close(driver.release)
+	service.Wait()
+
+	_, status = blink(assetSource, []any{nil})
+	require.Equal(t, api.Status_Good, status)
+	service.Wait()
+	assert.Equal(t, []time.Duration{5 * time.Second, DefaultDuration}, driver.durations)
+
+	_, status = blink(assetSource, []any{uint32(3600)})
+	assert.Equal(t, api.Status_BadOutOfRange, status)
+}
+
+func TestBlinkEndsAfterDuration(t *testing.T) {
+	var logged []error
+	service := New(&driverMock{}, func(err error) { logged = append(logged, err) })
+
+	require.True(t, service.Start(10*time.Millisecond))
+	service.Wait()
+	assert.Empty(t, logged)
+	assert.True(t, service.Start(time.Millisecond))
+	service.Wait()
+}
+
+type failingDriver struct{}
+
+func (failingDriver) Blink(context.Context, time.Duration) error {
+	return errors.New("no LED")
+}
+
+func TestBlinkLogsDriverErrors(t *testing.T) {
+	var logged []error
+	service := New(failingDriver{}, func(err error) { logged = append(logged, err) })
+
+	require.True(t, service.Start(time.Second))
+	service.Wait()
+	assert.EqualError(t, errors.Join(logged...), "no LED")
+}
@@ -0,0 +1,231 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/OI4/dnp-encoder-go"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/subscription"
+	"github.com/OI4/oi4-oec-service-go/service/container"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+	"os"
+	"path/filepath"
+)
+
+// configFileSuffix is appended to the DNP encoded Oi4Identifier of the source to name its persisted config
+const configFileSuffix = ".config.json"
+
+// SetConfigHandler handles Set Config requests: the values are validated against the config of the source and passed
+// to the apply function of the application. The accepted config is persisted and published with the CorrelationId of
+// the request, a rejected request is answered with a Bad status code and the unchanged config.
+func (app *Oi4ApplicationImpl) SetConfigHandler() api.MessageHandler {
+	return subscription.NewMessageHandler(app, func(_ api.ResourceType, source *api.Oi4Identifier, networkMessage api.NetworkMessage, topic *tp.Topic) {
+		baseSource := app.getSource(source)
+		if baseSource == nil {
+			app.logger.Infof("Ignoring Set request %s for unknown source on topic %s", networkMessage.MessageId, topic.ToString())
+			return
+		}
+
+		correlationId := networkMessage.MessageId
+		values, err := newSetConfig(networkMessage)
+		if err != nil {
+			app.rejectConfig(baseSource, correlationId, api.Status_BadDecodingError, err)
+			return
+		}
+		if err = app.setConfig(baseSource, values); err != nil {
+			code := api.Status_BadConfigurationError
+			if errors.Is(err, api.ErrInvalidConfig) {
+				code = api.Status_BadInvalidArgument
+			}
+			app.rejectConfig(baseSource, correlationId, code, err)
+			return
+		}
+
+		app.triggerSourcePublication(baseSource, api.ResourceConfig, nil, api.OnRequest, &correlationId)
+	}, subscription.WithSkipOwnMessage(false)) // the topic of a Set request contains the requested application
+}
+
+// subscribeSetConfig subscribes to the Set Config requests of the application and its assets
+func (app *Oi4ApplicationImpl) subscribeSetConfig() error {
+	filter := tp.NewFilter().ServiceType(app.serviceType).Oi4Identifier(app.oi4Identifier).Method(api.MethodSet).Resource(api.ResourceConfig)
+	return app.mqttClient.Subscribe(subscription.NewTopicSubscription(filter, app.SetConfigHandler()))
+}
+
+// newSetConfig combines the values of all DataSetMessages of a Set request
+func newSetConfig(networkMessage api.NetworkMessage) (api.SetConfig, error) {
+	result := make(api.SetConfig)
+	for _, message := range networkMessage.Messages {
+		if message == nil || message.DataSetWriterId == api.DataSetWriterIdPaginationRequest || message.DataSetWriterId == api.DataSetWriterIdLocale {
+			continue
+		}
+
+		values := make(api.SetConfig)
+		if err := decodePayload(message.Payload, &values); err != nil {
+			return nil, err
+		}
+		for group, groupValues := range values {
+			if result[group] == nil {
+				result[group] = make(map[string]any)
+			}
+			for name, value := range groupValues {
+				result[group][name] = value
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, errors.New("the request contains no config")
+	}
+	return result, nil
+}
+
+// setConfig validates the values and persists the config before the application applies it. A config which can not
+// be persisted is neither passed to the application nor applied to the source.
+func (app *Oi4ApplicationImpl) setConfig(source api.BaseSource, values api.SetConfig) error {
+	previous := source.GetConfig()
+	config, err := previous.Apply(values)
+	if err != nil {
+		return err
+	}
+
+	pending, err := app.writeConfig(source, config)
+	if err != nil {
+		return fmt.Errorf("failed to persist the config: %w", err)
+	}
+
+	if app.applyConfigFn != nil {
+		if err = app.applyConfigFn(source, config); err != nil {
+			discardConfig(pending)
+			return err
+		}
+	}
+
+	if err = app.commitConfig(source, pending); err != nil {
+		discardConfig(pending)
+		if app.applyConfigFn != nil {
+			// the application returns to the config, which stays persisted
+			_ = app.applyConfigFn(source, previous)
+		}
+		return fmt.Errorf("failed to persist the config: %w", err)
+	}
+	source.ApplyConfig(config)
+	return nil
+}
+
+func (app *Oi4ApplicationImpl) rejectConfig(source api.BaseSource, correlationId string, code api.StatusCode, err error) {
+	app.logger.Infof("Rejected Set Config request %s for %s: %v", correlationId, source.GetOi4Identifier().ToString(), err)
+
+	app.SendPublicationMessage(api.PublicationMessage{
+		Resource:      api.ResourceConfig,
+		Source:        source.GetOi4Identifier(),
+		CorrelationId: &correlationId,
+		Content:       []api.PublicationContent{{StatusCode: &code, Data: source.GetConfig()}},
+	})
+}
+
+func (app *Oi4ApplicationImpl) openConfigStorage(storage container.Storage) {
+	if storage.ApplicationSpecificStorages == nil {
+		return
+	}
+	app.configPath = storage.ApplicationSpecificStorages.ConfigurationPath
+}
+
+func (app *Oi4ApplicationImpl) configFile(source api.BaseSource) string {
+	return filepath.Join(app.configPath, dnp.Encode(source.GetOi4Identifier().ToString())+configFileSuffix)
+}
+
+// writeConfig writes the config to a temporary file next to the persisted config of the source, it replaces the
+// persisted config once it is committed. The returned path is empty without config storage.
+func (app *Oi4ApplicationImpl) writeConfig(source api.BaseSource, config api.PublishConfig) (string, error) {
+	if app.configPath == "" {
+		return "", nil
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(app.configPath, filepath.Base(app.configFile(source))+".*")
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		discardConfig(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// commitConfig replaces the persisted config of the source with the file written by writeConfig
+func (app *Oi4ApplicationImpl) commitConfig(source api.BaseSource, pending string) error {
+	if pending == "" {
+		return nil
+	}
+	return os.Rename(pending, app.configFile(source))
+}
+
+func discardConfig(pending string) {
+	if pending != "" {
+		_ = os.Remove(pending)
+	}
+}
+
+// restoreConfigs applies the persisted configs of the application and of the assets registered before the start
+func (app *Oi4ApplicationImpl) restoreConfigs() {
+	app.restoreConfig(app.applicationSource)
+
+	app.assetMutex.RLock()
+	sources := make([]api.BaseSource, 0, len(app.assets))
+	for _, asset := range app.assets {
+		sources = append(sources, asset.source)
+	}
+	app.assetMutex.RUnlock()
+
+	for _, source := range sources {
+		app.restoreConfig(source)
+	}
+}
+
+// restoreConfig applies the persisted config of a source, a config no longer matching the config of the source is
+// ignored
+func (app *Oi4ApplicationImpl) restoreConfig(source api.BaseSource) {
+	if app.configPath == "" {
+		return
+	}
+
+	data, err := os.ReadFile(app.configFile(source))
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	persisted := api.PublishConfig{}
+	if err == nil {
+		err = json.Unmarshal(data, &persisted)
+	}
+	if err == nil {
+		err = app.setConfig(source, persisted.Values())
+	}
+	if err != nil {
+		app.logger.Warnf("Failed to restore the config of %s: %v", source.GetOi4Identifier().ToString(), err)
+	}
+}
+
+// getSource returns the application source for nil or its own identifier, otherwise the source of the asset
+func (app *Oi4ApplicationImpl) getSource(source *api.Oi4Identifier) api.BaseSource {
+	if source == nil || source.Equals(app.oi4Identifier) {
+		return app.applicationSource
+	}
+
+	app.assetMutex.RLock()
+	defer app.assetMutex.RUnlock()
+	if asset, ok := app.assets[*source]; ok {
+		return asset.source
+	}
+	return nil
+}
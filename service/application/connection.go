@@ -0,0 +1,64 @@
+package application
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+)
+
+// AddConnectionListener registers a listener for the connection state of the application.
+// Listeners added before the start are notified about the initial connect as well.
+func (app *Oi4ApplicationImpl) AddConnectionListener(listener api.ConnectionListener) {
+	app.connectionListeners.Add(listener)
+}
+
+// IsConnected returns true if the application is connected to the broker
+func (app *Oi4ApplicationImpl) IsConnected() bool {
+	return app.mqttClient != nil && app.mqttClient.IsConnected()
+}
+
+func (app *Oi4ApplicationImpl) onConnectionStateChange(state api.ConnectionState, err error) {
+	switch state {
+	case api.ConnectionLost:
+		app.logger.Warnf("Connection to broker lost: %v", err)
+		app.degradeHealthWhileOffline()
+	case api.Reconnecting:
+		app.logger.Debug("Reconnecting to broker")
+	case api.Reconnected:
+		app.restoreHealthAfterOffline()
+		app.onReconnect()
+	}
+
+	app.connectionListeners.Notify(state, err)
+}
+
+// degradeHealthWhileOffline sets the health of the application source as configured by WithOfflineHealth
+func (app *Oi4ApplicationImpl) degradeHealthWhileOffline() {
+	if app.offlineHealth == nil {
+		return
+	}
+
+	app.offlineHealthMutex.Lock()
+	if app.healthBeforeOffline != nil {
+		app.offlineHealthMutex.Unlock()
+		return
+	}
+	health := app.applicationSource.GetHealth()
+	app.healthBeforeOffline = &health
+	app.offlineHealthMutex.Unlock()
+
+	offlineHealth := api.Health{Health: *app.offlineHealth, HealthScore: health.HealthScore}
+	if offlineHealth.Health == api.Health_Failure {
+		offlineHealth.HealthScore = 0
+	}
+	app.applicationSource.UpdateHealth(offlineHealth)
+}
+
+func (app *Oi4ApplicationImpl) restoreHealthAfterOffline() {
+	app.offlineHealthMutex.Lock()
+	health := app.healthBeforeOffline
+	app.healthBeforeOffline = nil
+	app.offlineHealthMutex.Unlock()
+
+	if health != nil {
+		app.applicationSource.UpdateHealth(*health)
+	}
+}
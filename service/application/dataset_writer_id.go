@@ -0,0 +1,120 @@
+package application
+
+import (
+	"errors"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/opc"
+)
+
+const (
+	// MethodGetDataSetWriterId returns the DataSetWriterId of a publication:
+	// Get(Resource, Source, Filter) → (DataSetWriterId)
+	MethodGetDataSetWriterId = "Get"
+	// MethodAssignDataSetWriterId reassigns the DataSetWriterId of a publication, a new id is assigned if the request
+	// contains none: Assign(Resource, Source, Filter, DataSetWriterId) → (DataSetWriterId)
+	MethodAssignDataSetWriterId = "Assign"
+)
+
+// GetDataSetWriterId returns the DataSetWriterId of the publication of the resource, source and filter. The ids are
+// unique within the application.
+func (app *Oi4ApplicationImpl) GetDataSetWriterId(resource api.ResourceType, source *api.Oi4Identifier, filter *api.Filter) uint16 {
+	return app.dataSetWriterIds.Get(resource, source, filter)
+}
+
+// AssignDataSetWriterId reassigns the DataSetWriterId of a publication, an id of 0 assigns an unused id. The
+// PublicationList of the source is published with the new id.
+func (app *Oi4ApplicationImpl) AssignDataSetWriterId(publication api.Publication, id uint16) (uint16, error) {
+	id, err := app.dataSetWriterIds.Assign(publication.GetResource(), publication.GetSource(), publication.GetFilter(), id)
+	if err != nil {
+		return 0, err
+	}
+
+	if source := app.getSource(publication.GetSource()); source != nil {
+		app.ResourceChanged(api.ResourcePublicationList, source, nil)
+	}
+	return id, nil
+}
+
+// registerDataSetWriterIdService registers the NewDataSetWriterId service of the application, the Source of a request
+// is the application or one of its assets
+func (app *Oi4ApplicationImpl) registerDataSetWriterIdService() error {
+	arguments := []api.MethodArgument{
+		api.NewArgument[api.ResourceType]("Resource"),
+		api.NewOptionalArgument[string]("Source"),
+		api.NewOptionalArgument[string]("Filter"),
+	}
+
+	err := app.RegisterMethod(api.ResourceNewDataSetWriterId, MethodGetDataSetWriterId, api.Method{
+		InputArguments: arguments,
+		Handler: func(_ api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+			publication, status := app.findPublication(inputArguments)
+			if status != api.Status_Good {
+				return nil, status
+			}
+			return []any{publication.GetDataSetWriterId()}, api.Status_Good
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return app.RegisterMethod(api.ResourceNewDataSetWriterId, MethodAssignDataSetWriterId, api.Method{
+		InputArguments: append(arguments, api.NewOptionalArgument[uint16]("DataSetWriterId")),
+		Handler: func(_ api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+			publication, status := app.findPublication(inputArguments)
+			if status != api.Status_Good {
+				return nil, status
+			}
+
+			requested, _ := inputArguments[3].(uint16)
+			id, err := app.AssignDataSetWriterId(publication, requested)
+			switch {
+			case errors.Is(err, opc.ErrDataSetWriterIdReserved):
+				return nil, api.Status_BadInvalidArgument
+			case errors.Is(err, opc.ErrDataSetWriterIdInUse):
+				return nil, api.Status_BadEntryExists
+			case err != nil:
+				return nil, api.Status_BadUnexpectedError
+			}
+			return []any{id}, api.Status_Good
+		},
+	})
+}
+
+// findPublication returns the publication of the Resource, Source and Filter arguments. Without Source the publication
+// of the application is returned.
+func (app *Oi4ApplicationImpl) findPublication(inputArguments []any) (api.Publication, api.StatusCode) {
+	resource := inputArguments[0].(api.ResourceType)
+	source := app.oi4Identifier
+	if value, ok := inputArguments[1].(string); ok && value != "" {
+		var err error
+		if source, err = api.ParseOi4Identifier(value, false); err != nil {
+			return nil, api.Status_BadInvalidArgument
+		}
+	}
+	var filter *api.Filter
+	if value, ok := inputArguments[2].(string); ok && value != "" {
+		filter = api.NewFilter(value)
+	}
+
+	var provider api.PublicationProvider = app
+	if !source.Equals(app.oi4Identifier) {
+		app.assetMutex.RLock()
+		asset, ok := app.assets[*source]
+		app.assetMutex.RUnlock()
+		if !ok {
+			return nil, api.Status_BadNotFound
+		}
+		provider = asset
+	}
+
+	for _, publication := range provider.GetPublications() {
+		if publication.GetResource() != resource {
+			continue
+		}
+		if (publication.GetFilter() == nil && filter == nil) || api.FilterEquals(publication.GetFilter(), filter) {
+			return publication, api.Status_Good
+		}
+	}
+	return nil, api.Status_BadNotFound
+}
@@ -0,0 +1,184 @@
+package application
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/subscription"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+)
+
+// DelHandler handles Del requests for entries of the PublicationList and the SubscriptionList. The entries are given
+// by the DataSetMessages of the request or by the filter of the topic. Matching publications are stopped and removed,
+// matching subscriptions are unsubscribed and removed. The updated list is published with the CorrelationId of the
+// request, a request matching no entry is answered with a Bad status code and the unchanged list.
+func (app *Oi4ApplicationImpl) DelHandler() api.MessageHandler {
+	return subscription.NewMessageHandler(app, func(resource api.ResourceType, source *api.Oi4Identifier, networkMessage api.NetworkMessage, topic *tp.Topic) {
+		baseSource := app.getSource(source)
+		if baseSource == nil {
+			app.logger.Infof("Ignoring Del request %s for unknown source on topic %s", networkMessage.MessageId, topic.ToString())
+			return
+		}
+
+		var removed int
+		var err error
+		switch resource {
+		case api.ResourcePublicationList:
+			removed, err = app.deletePublications(baseSource, networkMessage, topic.Filter)
+		case api.ResourceSubscriptionList:
+			removed, err = app.deleteSubscriptions(baseSource, networkMessage, topic.Filter)
+		default:
+			app.logger.Infof("Del is not supported for resource %s", resource)
+			return
+		}
+
+		correlationId := networkMessage.MessageId
+		if err != nil {
+			app.logger.Infof("Invalid Del request %s on topic %s: %v", networkMessage.MessageId, topic.ToString(), err)
+			app.rejectDel(baseSource, resource, correlationId, api.Status_BadDecodingError)
+			return
+		}
+		if removed == 0 {
+			app.logger.Infof("Del request %s on topic %s matches no entry", networkMessage.MessageId, topic.ToString())
+			app.rejectDel(baseSource, resource, correlationId, api.Status_BadNotFound)
+			return
+		}
+
+		app.triggerSourcePublication(baseSource, resource, nil, api.OnRequest, &correlationId)
+	}, subscription.WithSkipOwnMessage(false)) // the topic of a Del request contains the requested application
+}
+
+func (app *Oi4ApplicationImpl) rejectDel(source api.BaseSource, resource api.ResourceType, correlationId string, code api.StatusCode) {
+	entries := source.Get(resource, nil)
+	content := make([]api.PublicationContent, 0, max(len(entries), 1))
+	for _, entry := range entries {
+		content = append(content, api.PublicationContent{StatusCode: &code, Data: entry})
+	}
+	if len(content) == 0 {
+		// the status code is sent even if the list is empty
+		content = append(content, api.PublicationContent{StatusCode: &code})
+	}
+
+	app.SendPublicationMessage(api.PublicationMessage{
+		Resource:      resource,
+		Source:        source.GetOi4Identifier(),
+		CorrelationId: &correlationId,
+		Content:       content,
+	})
+}
+
+// subscribeDel subscribes to the Del requests of the application and its assets
+func (app *Oi4ApplicationImpl) subscribeDel() error {
+	filter := tp.NewFilter().ServiceType(app.serviceType).Oi4Identifier(app.oi4Identifier).Method(api.MethodDel)
+	return app.mqttClient.Subscribe(subscription.NewTopicSubscription(filter, app.DelHandler()))
+}
+
+// deletePublications removes the publications of the source matching the entries of the request or the filter of
+// the topic, and returns the number of removed publications. The publications of the lists answer the request and are
+// kept.
+func (app *Oi4ApplicationImpl) deletePublications(source api.BaseSource, networkMessage api.NetworkMessage, filter *api.Filter) (int, error) {
+	entries, err := decodeListEntries[api.PublicationList](networkMessage)
+	if err != nil {
+		return 0, err
+	}
+
+	var provider api.PublicationProvider = app
+	remove := app.RemovePublication
+	if !source.Equals(app.applicationSource) {
+		app.assetMutex.RLock()
+		asset, ok := app.assets[*source.GetOi4Identifier()]
+		app.assetMutex.RUnlock()
+		if !ok {
+			return 0, nil
+		}
+		provider = asset
+		remove = asset.RemovePublication
+	}
+
+	removed := 0
+	for _, publication := range provider.GetPublications() {
+		if publication.GetResource() == api.ResourcePublicationList || publication.GetResource() == api.ResourceSubscriptionList {
+			continue
+		}
+		matches := api.FilterEquals(publication.GetFilter(), filter)
+		for _, entry := range entries {
+			matches = matches || matchesPublicationEntry(publication, entry)
+		}
+		if matches {
+			app.logger.Infof("Removing publication %s of %s", publication.GetResource(), source.GetOi4Identifier().ToString())
+			remove(publication)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// deleteSubscriptions unsubscribes the subscriptions of the application with the topic of an entry of the request or
+// the topic given by the filter of the request topic, and returns the number of removed subscriptions. The
+// subscriptions belong to the application, a request for an asset removes nothing.
+func (app *Oi4ApplicationImpl) deleteSubscriptions(source api.BaseSource, networkMessage api.NetworkMessage, filter *api.Filter) (int, error) {
+	entries, err := decodeListEntries[api.SubscriptionList](networkMessage)
+	if err != nil {
+		return 0, err
+	}
+	if !source.Equals(app.applicationSource) {
+		return 0, nil
+	}
+	if filter != nil {
+		entries = append(entries, api.SubscriptionList{TopicPath: filter.String()})
+	}
+
+	removed := 0
+	for _, current := range app.GetSubscriptions() {
+		for _, entry := range entries {
+			if current.GetTopic() != entry.TopicPath {
+				continue
+			}
+			app.logger.Infof("Removing subscription %s", current.GetTopic())
+			if err = app.RemoveSubscription(current); err != nil {
+				app.logger.Warnf("Failed to remove subscription %s: %v", current.GetTopic(), err)
+				break
+			}
+			removed++
+			break
+		}
+	}
+	return removed, nil
+}
+
+// matchesPublicationEntry compares resource and filter, and the DataSetWriterId if the entry defines it
+func matchesPublicationEntry(publication api.Publication, entry api.PublicationList) bool {
+	if publication.GetResource() != entry.ResourceType {
+		return false
+	}
+	if entry.DataSetWriterId != 0 && publication.GetDataSetWriterId() != entry.DataSetWriterId {
+		return false
+	}
+	return (publication.GetFilter() == nil && entry.Filter == nil) || api.FilterEquals(publication.GetFilter(), entry.Filter)
+}
+
+// decodeListEntries returns the entries of all DataSetMessages, a payload is either a single entry or a list of entries
+func decodeListEntries[T any](networkMessage api.NetworkMessage) ([]T, error) {
+	entries := make([]T, 0)
+	for _, message := range networkMessage.Messages {
+		if message == nil || message.DataSetWriterId == api.DataSetWriterIdPaginationRequest || message.DataSetWriterId == api.DataSetWriterIdLocale {
+			continue
+		}
+
+		if list, ok := message.Payload.([]any); ok {
+			for _, element := range list {
+				var entry T
+				if err := decodePayload(element, &entry); err != nil {
+					return nil, err
+				}
+				entries = append(entries, entry)
+			}
+			continue
+		}
+
+		var entry T
+		if err := decodePayload(message.Payload, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
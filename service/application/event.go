@@ -0,0 +1,16 @@
+package application
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+)
+
+// SendEvent publishes an event of the application or of an asset on the topic of its category
+func (app *Oi4ApplicationImpl) SendEvent(source *api.Oi4Identifier, event api.Event) {
+	category := string(event.Category)
+	app.SendPublicationMessage(api.PublicationMessage{
+		Resource: api.ResourceEvent,
+		Source:   source,
+		Category: &category,
+		Content:  []api.PublicationContent{{Data: &event}},
+	})
+}
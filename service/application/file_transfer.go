@@ -0,0 +1,42 @@
+package application
+
+import (
+	"fmt"
+	"github.com/OI4/oi4-oec-service-go/service/application/filetransfer"
+	"github.com/OI4/oi4-oec-service-go/service/container"
+	"os"
+	"path/filepath"
+)
+
+// fileTransferDirectory is the sandbox below the data path, it separates the transferred files from internal data
+// like the outbox
+const fileTransferDirectory = "files"
+
+// openFileTransfer registers the FileUpload and FileDownload services with a sandbox in the data path of the container
+func (app *Oi4ApplicationImpl) openFileTransfer(storage container.Storage) error {
+	if app.fileTransferPermission == nil || storage.ApplicationSpecificStorages == nil || storage.ApplicationSpecificStorages.DataPath == "" {
+		return nil
+	}
+
+	sandbox := filepath.Join(storage.ApplicationSpecificStorages.DataPath, fileTransferDirectory)
+	if err := os.MkdirAll(sandbox, 0o750); err != nil {
+		return fmt.Errorf("failed to create file transfer directory: %w", err)
+	}
+
+	var err error
+	app.fileTransfer, err = filetransfer.New(sandbox, app.maxPacketSize, app.fileTransferPermission)
+	if err != nil {
+		return err
+	}
+	return app.fileTransfer.Register(app)
+}
+
+func (app *Oi4ApplicationImpl) closeFileTransfer() {
+	if app.fileTransfer == nil {
+		return
+	}
+	if err := app.fileTransfer.Close(); err != nil {
+		app.logger.Warnf("Failed to close the file transfer sandbox: %v", err)
+	}
+	app.fileTransfer = nil
+}
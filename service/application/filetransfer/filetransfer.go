@@ -0,0 +1,326 @@
+// Package filetransfer provides the FileUpload and FileDownload common services. Files are transferred in chunks,
+// every chunk and the complete file are verified with a SHA-256 checksum. All paths are relative to a sandbox, files
+// outside of it can not be accessed.
+//
+// FileUpload:
+//   - Write(Path, Offset, Data, Checksum) → (Offset) appends a chunk to the upload. Offset 0 starts a new upload, any
+//     other offset continues an interrupted upload and must match the size received so far, otherwise the reply
+//     contains the expected offset.
+//   - Commit(Path, Checksum) replaces the file with the verified upload.
+//
+// FileDownload:
+//   - Info(Path) → (Size, Checksum, ChunkSize)
+//   - Read(Path, Offset, Length) → (Data, Checksum) returns a chunk of at most ChunkSize bytes.
+package filetransfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	MethodWrite  = "Write"
+	MethodCommit = "Commit"
+	MethodInfo   = "Info"
+	MethodRead   = "Read"
+
+	// DefaultChunkSize is the chunk size if the packet size is not limited
+	DefaultChunkSize = 256 * 1024
+	// minChunkSize is used for very small packet sizes, the broker rejects such chunks
+	minChunkSize = 1024
+	// envelopeSize is reserved for the ServiceNetworkMessage and the other arguments of a chunk
+	envelopeSize = 1024
+	// partSuffix is appended to the path of an upload until it is committed
+	partSuffix = ".part"
+)
+
+// Access is the kind of access to a file
+type Access string
+
+const (
+	AccessRead  Access = "Read"
+	AccessWrite Access = "Write"
+)
+
+// PermissionFn decides whether a file may be accessed by a request for the source, the path is relative to the sandbox
+type PermissionFn func(source api.BaseSource, path string, access Access) bool
+
+type Service struct {
+	root       *os.Root
+	chunkSize  int
+	permission PermissionFn
+}
+
+// New creates the services for the sandbox directory. The chunks of downloads are sized, so the replies encoded as
+// JSON stay below maxPacketSize bytes, 0 if not limited.
+func New(sandbox string, maxPacketSize int, permission PermissionFn) (*Service, error) {
+	if permission == nil {
+		return nil, errors.New("a permission function is required")
+	}
+
+	root, err := os.OpenRoot(sandbox)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{root: root, chunkSize: ChunkSize(maxPacketSize), permission: permission}, nil
+}
+
+// ChunkSize returns the number of bytes of a chunk, which fits Base64 encoded into a packet of maxPacketSize bytes
+func ChunkSize(maxPacketSize int) int {
+	if maxPacketSize <= 0 {
+		return DefaultChunkSize
+	}
+	return max((maxPacketSize-envelopeSize)/4*3, minChunkSize)
+}
+
+// Register registers the methods of both services
+func (s *Service) Register(registry api.MethodRegistry) error {
+	methods := []struct {
+		service  api.ResourceType
+		methodId string
+		method   api.Method
+	}{
+		{api.ResourceFileUpload, MethodWrite, api.Method{
+			InputArguments: []api.MethodArgument{api.NewArgument[string]("Path"), api.NewArgument[uint64]("Offset"), api.NewArgument[[]byte]("Data"), api.NewArgument[string]("Checksum")},
+			Handler:        s.write,
+		}},
+		{api.ResourceFileUpload, MethodCommit, api.Method{
+			InputArguments: []api.MethodArgument{api.NewArgument[string]("Path"), api.NewArgument[string]("Checksum")},
+			Handler:        s.commit,
+		}},
+		{api.ResourceFileDownload, MethodInfo, api.Method{
+			InputArguments: []api.MethodArgument{api.NewArgument[string]("Path")},
+			Handler:        s.info,
+		}},
+		{api.ResourceFileDownload, MethodRead, api.Method{
+			InputArguments: []api.MethodArgument{api.NewArgument[string]("Path"), api.NewArgument[uint64]("Offset"), api.NewOptionalArgument[uint32]("Length")},
+			Handler:        s.read,
+		}},
+	}
+
+	for _, current := range methods {
+		if err := registry.RegisterMethod(current.service, current.methodId, current.method); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadFile reads a file of the sandbox without permission check, e.g. an uploaded firmware image
+func (s *Service) ReadFile(name string) ([]byte, error) {
+	name = filepath.ToSlash(filepath.Clean(name))
+	if !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("the path %s is outside of the sandbox", name)
+	}
+
+	file, err := s.root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// Close releases the sandbox
+func (s *Service) Close() error {
+	return s.root.Close()
+}
+
+func (s *Service) write(source api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	name, status := s.authorize(source, inputArguments[0].(string), AccessWrite)
+	if status != api.Status_Good {
+		return nil, status
+	}
+	offset := inputArguments[1].(uint64)
+	data := inputArguments[2].([]byte)
+	if Checksum(data) != inputArguments[3].(string) {
+		return nil, api.Status_BadDataLost
+	}
+
+	if err := s.mkdirAll(path.Dir(name)); err != nil {
+		return nil, statusOf(err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	file, err := s.root.OpenFile(name+partSuffix, flags, 0o600)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	if uint64(info.Size()) != offset {
+		return []any{uint64(info.Size())}, api.Status_BadSequenceNumberInvalid
+	}
+
+	if _, err = file.WriteAt(data, int64(offset)); err != nil {
+		return nil, statusOf(err)
+	}
+	return []any{offset + uint64(len(data))}, api.Status_Good
+}
+
+func (s *Service) commit(source api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	name, status := s.authorize(source, inputArguments[0].(string), AccessWrite)
+	if status != api.Status_Good {
+		return nil, status
+	}
+
+	part, err := s.root.Open(name + partSuffix)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	checksum, err := checksumOf(part)
+	if err != nil {
+		_ = part.Close()
+		return nil, statusOf(err)
+	}
+	if checksum != inputArguments[1].(string) {
+		_ = part.Close()
+		return nil, api.Status_BadDataLost
+	}
+
+	// the root does not support renames, so the upload is copied into place
+	if err = s.copyTo(part, name); err != nil {
+		_ = part.Close()
+		return nil, statusOf(err)
+	}
+	_ = part.Close()
+
+	if err = s.root.Remove(name + partSuffix); err != nil {
+		return nil, statusOf(err)
+	}
+	return nil, api.Status_Good
+}
+
+func (s *Service) info(source api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	name, status := s.authorize(source, inputArguments[0].(string), AccessRead)
+	if status != api.Status_Good {
+		return nil, status
+	}
+
+	file, err := s.root.Open(name)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	if info.IsDir() {
+		return nil, api.Status_BadNotReadable
+	}
+	checksum, err := checksumOf(file)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	return []any{uint64(info.Size()), checksum, uint32(s.chunkSize)}, api.Status_Good
+}
+
+func (s *Service) read(source api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	name, status := s.authorize(source, inputArguments[0].(string), AccessRead)
+	if status != api.Status_Good {
+		return nil, status
+	}
+	offset := inputArguments[1].(uint64)
+	length := s.chunkSize
+	if requested, ok := inputArguments[2].(uint32); ok && requested > 0 {
+		length = min(int(requested), s.chunkSize)
+	}
+
+	file, err := s.root.Open(name)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	defer file.Close()
+
+	data := make([]byte, length)
+	n, err := file.ReadAt(data, int64(offset))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, statusOf(err)
+	}
+	data = data[:n]
+	return []any{data, Checksum(data)}, api.Status_Good
+}
+
+// authorize returns the cleaned path in the sandbox if the source may access it
+func (s *Service) authorize(source api.BaseSource, name string, access Access) (string, api.StatusCode) {
+	name = filepath.ToSlash(filepath.Clean(name))
+	if !filepath.IsLocal(name) || strings.HasSuffix(name, partSuffix) {
+		return "", api.Status_BadInvalidArgument
+	}
+	if !s.permission(source, name, access) {
+		return "", api.Status_BadUserAccessDenied
+	}
+	return name, api.Status_Good
+}
+
+func (s *Service) mkdirAll(dir string) error {
+	if dir == "." {
+		return nil
+	}
+	if err := s.mkdirAll(path.Dir(dir)); err != nil {
+		return err
+	}
+	if err := s.root.Mkdir(dir, 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) copyTo(source io.ReadSeeker, name string) error {
+	if _, err := source.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	target, err := s.root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(target, source); err != nil {
+		_ = target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+// Checksum returns the hex encoded SHA-256 checksum of the data
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func checksumOf(reader io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func statusOf(err error) api.StatusCode {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return api.Status_BadNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return api.Status_BadUserAccessDenied
+	default:
+		return api.Status_BadUnexpectedError
+	}
+}
@@ -0,0 +1,109 @@
+package filetransfer
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/internal/methodtest"
+	"github.com/OI4/oi4-oec-service-go/service/application/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestService(t *testing.T, maxPacketSize int) (methodtest.Registry, string) {
+	sandbox := t.TempDir()
+	service, err := New(sandbox, maxPacketSize, func(_ api.BaseSource, path string, access Access) bool {
+		return path != "secret.txt" && (access == AccessRead || filepath.Dir(path) != "logs")
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = service.Close() })
+
+	methods := make(methodtest.Registry)
+	require.NoError(t, service.Register(methods))
+	return methods, sandbox
+}
+
+func call(methods methodtest.Registry, service api.ResourceType, methodId string, inputArguments ...any) ([]any, api.StatusCode) {
+	return methods.Get(service, methodId).Handler(source.NewApplicationSourceImpl(api.MasterAssetModel{}), inputArguments)
+}
+
+func TestUploadInChunksWithResume(t *testing.T) {
+	methods, sandbox := newTestService(t, 0)
+	first, second := []byte("recipe "), []byte("v2")
+
+	output, status := call(methods, api.ResourceFileUpload, MethodWrite, "recipes/a.txt", uint64(0), first, Checksum(first))
+	require.Equal(t, api.Status_Good, status)
+	assert.Equal(t, []any{uint64(len(first))}, output)
+
+	// a wrong offset is answered with the offset to resume from
+	output, status = call(methods, api.ResourceFileUpload, MethodWrite, "recipes/a.txt", uint64(3), second, Checksum(second))
+	assert.Equal(t, api.Status_BadSequenceNumberInvalid, status)
+	assert.Equal(t, []any{uint64(len(first))}, output)
+
+	_, status = call(methods, api.ResourceFileUpload, MethodWrite, "recipes/a.txt", uint64(len(first)), second, Checksum(first))
+	assert.Equal(t, api.Status_BadDataLost, status)
+
+	_, status = call(methods, api.ResourceFileUpload, MethodWrite, "recipes/a.txt", uint64(len(first)), second, Checksum(second))
+	require.Equal(t, api.Status_Good, status)
+
+	// the upload is not visible before the commit
+	assert.NoFileExists(t, filepath.Join(sandbox, "recipes", "a.txt"))
+	_, status = call(methods, api.ResourceFileUpload, MethodCommit, "recipes/a.txt", Checksum([]byte("other")))
+	assert.Equal(t, api.Status_BadDataLost, status)
+
+	_, status = call(methods, api.ResourceFileUpload, MethodCommit, "recipes/a.txt", Checksum([]byte("recipe v2")))
+	require.Equal(t, api.Status_Good, status)
+	content, err := os.ReadFile(filepath.Join(sandbox, "recipes", "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "recipe v2", string(content))
+	assert.NoFileExists(t, filepath.Join(sandbox, "recipes", "a.txt"+partSuffix))
+}
+
+func TestDownloadInChunks(t *testing.T) {
+	methods, sandbox := newTestService(t, 2048)
+	chunkSize := ChunkSize(2048)
+	content := make([]byte, chunkSize+10)
+	for i := range content {
+		content[i] = byte(i)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(sandbox, "logs"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(sandbox, "logs", "app.log"), content, 0o600))
+
+	output, status := call(methods, api.ResourceFileDownload, MethodInfo, "logs/app.log")
+	require.Equal(t, api.Status_Good, status)
+	assert.Equal(t, []any{uint64(len(content)), Checksum(content), uint32(chunkSize)}, output)
+
+	// the length is limited to the chunk size
+	output, status = call(methods, api.ResourceFileDownload, MethodRead, "logs/app.log", uint64(0), uint32(chunkSize*2))
+	require.Equal(t, api.Status_Good, status)
+	assert.Equal(t, []any{content[:chunkSize], Checksum(content[:chunkSize])}, output)
+
+	output, status = call(methods, api.ResourceFileDownload, MethodRead, "logs/app.log", uint64(chunkSize), nil)
+	require.Equal(t, api.Status_Good, status)
+	assert.Equal(t, content[chunkSize:], output[0])
+
+	_, status = call(methods, api.ResourceFileDownload, MethodInfo, "logs/missing.log")
+	assert.Equal(t, api.Status_BadNotFound, status)
+}
+
+func TestSandboxAndPermissions(t *testing.T) {
+	methods, sandbox := newTestService(t, 0)
+	require.NoError(t, os.WriteFile(filepath.Join(sandbox, "secret.txt"), []byte("secret"), 0o600))
+	data := []byte("x")
+
+	_, status := call(methods, api.ResourceFileDownload, MethodInfo, "../outside.txt")
+	assert.Equal(t, api.Status_BadInvalidArgument, status)
+	_, status = call(methods, api.ResourceFileDownload, MethodInfo, "/etc/passwd")
+	assert.Equal(t, api.Status_BadInvalidArgument, status)
+	_, status = call(methods, api.ResourceFileDownload, MethodRead, "secret.txt", uint64(0), nil)
+	assert.Equal(t, api.Status_BadUserAccessDenied, status)
+	_, status = call(methods, api.ResourceFileUpload, MethodWrite, "logs/app.log", uint64(0), data, Checksum(data))
+	assert.Equal(t, api.Status_BadUserAccessDenied, status)
+}
+
+func TestChunkSize(t *testing.T) {
+	assert.Equal(t, DefaultChunkSize, ChunkSize(0))
+	assert.Equal(t, minChunkSize, ChunkSize(100))
+	assert.Equal(t, 3072, ChunkSize(5120))
+}
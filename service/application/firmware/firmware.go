@@ -0,0 +1,303 @@
+// Package firmware provides the FirmwareUpdate common service of an asset. An update runs through the states
+// Idle → Downloading → Verifying → Applying → Rebooting → Done, or ends in Failed. Every state is published as event
+// and the health of the asset is CHECK_FUNCTION while the update runs. After a successful update the SoftwareRevision
+// and the RevisionCounter of the MAM are updated.
+//
+// FirmwareUpdate:
+//   - Update(Version, Checksum, Path, Reference, Signature) starts the update with an image uploaded to the file
+//     transfer sandbox (Path) or downloaded by the driver (Reference). The image is verified with its SHA-256 checksum
+//     and, if given, with its signature.
+//   - State() → (State, Progress, Error)
+package firmware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"sync"
+)
+
+const (
+	MethodUpdate = "Update"
+	MethodState  = "State"
+
+	// EventNumber is the number of the firmware update events, the state is part of the details
+	EventNumber = 2200
+)
+
+// ErrUpdateRunning is returned when an update is requested while another one is running
+var ErrUpdateRunning = errors.New("an update is already running")
+
+type State string
+
+const (
+	StateIdle        State = "Idle"
+	StateDownloading State = "Downloading"
+	StateVerifying   State = "Verifying"
+	StateApplying    State = "Applying"
+	StateRebooting   State = "Rebooting"
+	StateDone        State = "Done"
+	StateFailed      State = "Failed"
+)
+
+// progress of the update when a state is entered, in percent
+var progress = map[State]byte{
+	StateIdle:        0,
+	StateDownloading: 0,
+	StateVerifying:   25,
+	StateApplying:    50,
+	StateRebooting:   75,
+	StateDone:        100,
+	StateFailed:      0,
+}
+
+// Driver performs the device specific steps of an update, it is provided by the asset
+type Driver interface {
+	// Download returns the image of a reference, e.g. a URL
+	Download(ctx context.Context, reference string) ([]byte, error)
+	// Apply installs the verified image
+	Apply(ctx context.Context, image []byte, version string) error
+	// Reboot restarts the asset with the installed image
+	Reboot(ctx context.Context) error
+}
+
+// SignatureVerifier is implemented by drivers supporting signed images, an update with signature is rejected if the
+// driver does not implement it
+type SignatureVerifier interface {
+	VerifySignature(image []byte, signature []byte) error
+}
+
+// Request of an update, either Path or Reference is set
+type Request struct {
+	Version   string
+	Checksum  string
+	Path      string
+	Reference string
+	Signature []byte
+}
+
+// Details are published as details of the firmware update events
+type Details struct {
+	State    State  `json:"State"`
+	Progress byte   `json:"Progress"`
+	Version  string `json:"Version,omitempty"`
+	Error    string `json:"Error,omitempty"`
+}
+
+type Updater struct {
+	source api.AssetSource
+	driver Driver
+
+	// readImage reads an image of the file transfer sandbox
+	readImage func(path string) ([]byte, error)
+	// sendEvent publishes an event of the asset
+	sendEvent func(event api.Event)
+
+	state        State
+	err          error
+	healthBefore api.Health
+	mutex        sync.Mutex
+	done         chan struct{}
+}
+
+// NewUpdater creates the update service of the asset source. readImage reads uploaded images, it may be nil if
+// images are only referenced.
+func NewUpdater(source api.AssetSource, driver Driver, readImage func(path string) ([]byte, error), sendEvent func(event api.Event)) *Updater {
+	return &Updater{
+		source:    source,
+		driver:    driver,
+		readImage: readImage,
+		sendEvent: sendEvent,
+		state:     StateIdle,
+	}
+}
+
+// Register registers the methods of the service
+func (u *Updater) Register(registry api.MethodRegistry) error {
+	err := registry.RegisterMethod(api.ResourceFirmwareUpdate, MethodUpdate, api.Method{
+		InputArguments: []api.MethodArgument{
+			api.NewArgument[string]("Version"),
+			api.NewArgument[string]("Checksum"),
+			api.NewOptionalArgument[string]("Path"),
+			api.NewOptionalArgument[string]("Reference"),
+			api.NewOptionalArgument[[]byte]("Signature"),
+		},
+		Handler: u.update,
+	})
+	if err != nil {
+		return err
+	}
+
+	return registry.RegisterMethod(api.ResourceFirmwareUpdate, MethodState, api.Method{
+		Handler: func(api.BaseSource, []any) ([]any, api.StatusCode) {
+			state, progress, err := u.State()
+			message := ""
+			if err != nil {
+				message = err.Error()
+			}
+			return []any{state, progress, message}, api.Status_Good
+		},
+	})
+}
+
+// State returns the current state, its progress in percent and the error of a failed update
+func (u *Updater) State() (State, byte, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	return u.state, progress[u.state], u.err
+}
+
+// Start runs an update in the background, it fails if an update is already running
+func (u *Updater) Start(request Request) error {
+	if request.Version == "" || request.Checksum == "" || (request.Path == "") == (request.Reference == "") {
+		return errors.New("an update requires a version, a checksum and either a path or a reference")
+	}
+	if request.Path != "" && u.readImage == nil {
+		return errors.New("uploaded images are not supported without file transfer")
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	if u.done != nil {
+		return ErrUpdateRunning
+	}
+
+	u.err = nil
+	u.done = make(chan struct{})
+	u.healthBefore = u.source.GetHealth()
+	u.source.UpdateHealth(api.Health{Health: api.Health_CheckFunction, HealthScore: u.healthBefore.HealthScore})
+	go u.run(request, u.done)
+	return nil
+}
+
+// Wait blocks until the running update is finished
+func (u *Updater) Wait() {
+	u.mutex.Lock()
+	done := u.done
+	u.mutex.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+func (u *Updater) update(_ api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	request := Request{
+		Version:  inputArguments[0].(string),
+		Checksum: inputArguments[1].(string),
+	}
+	if path, ok := inputArguments[2].(string); ok {
+		request.Path = path
+	}
+	if reference, ok := inputArguments[3].(string); ok {
+		request.Reference = reference
+	}
+	if signature, ok := inputArguments[4].([]byte); ok {
+		request.Signature = signature
+	}
+
+	if err := u.Start(request); errors.Is(err, ErrUpdateRunning) {
+		return nil, api.Status_BadInvalidState
+	} else if err != nil {
+		return nil, api.Status_BadInvalidArgument
+	}
+	return nil, api.Status_Good
+}
+
+func (u *Updater) run(request Request, done chan struct{}) {
+	ctx := context.Background()
+	err := u.install(ctx, request)
+	if err != nil {
+		u.transition(StateFailed, request.Version, err)
+		u.source.UpdateHealth(api.Health{Health: api.Health_MaintenanceRequired, HealthScore: u.healthBefore.HealthScore})
+	} else {
+		mam := u.source.GetMasterAssetModel()
+		mam.SoftwareRevision = request.Version
+		mam.RevisionCounter++
+		u.source.UpdateMasterAssetModel(mam)
+
+		u.transition(StateDone, request.Version, nil)
+		u.source.UpdateHealth(u.healthBefore)
+	}
+
+	u.mutex.Lock()
+	u.done = nil
+	u.mutex.Unlock()
+	close(done)
+}
+
+func (u *Updater) install(ctx context.Context, request Request) error {
+	u.transition(StateDownloading, request.Version, nil)
+
+	var image []byte
+	var err error
+	if request.Path != "" {
+		image, err = u.readImage(request.Path)
+	} else {
+		image, err = u.driver.Download(ctx, request.Reference)
+	}
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+
+	u.transition(StateVerifying, request.Version, nil)
+	if err = u.verify(image, request); err != nil {
+		return err
+	}
+
+	u.transition(StateApplying, request.Version, nil)
+	if err = u.driver.Apply(ctx, image, request.Version); err != nil {
+		return fmt.Errorf("apply failed: %w", err)
+	}
+
+	u.transition(StateRebooting, request.Version, nil)
+	if err = u.driver.Reboot(ctx); err != nil {
+		return fmt.Errorf("reboot failed: %w", err)
+	}
+	return nil
+}
+
+func (u *Updater) verify(image []byte, request Request) error {
+	sum := sha256.Sum256(image)
+	if hex.EncodeToString(sum[:]) != request.Checksum {
+		return errors.New("checksum mismatch")
+	}
+
+	if len(request.Signature) == 0 {
+		return nil
+	}
+	verifier, ok := u.driver.(SignatureVerifier)
+	if !ok {
+		return errors.New("signed images are not supported by the driver")
+	}
+	if err := verifier.VerifySignature(image, request.Signature); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	return nil
+}
+
+func (u *Updater) transition(state State, version string, err error) {
+	u.mutex.Lock()
+	u.state = state
+	u.err = err
+	u.mutex.Unlock()
+
+	if u.sendEvent == nil {
+		return
+	}
+
+	details := Details{State: state, Progress: progress[state], Version: version}
+	if err != nil {
+		details.Error = err.Error()
+	}
+	u.sendEvent(api.Event{
+		Number:      EventNumber,
+		Description: fmt.Sprintf("Firmware update %s", state),
+		Category:    api.EventCategoryGENERIC,
+		Details:     details,
+	})
+}
@@ -0,0 +1,208 @@
+package firmware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/internal/methodtest"
+	"github.com/OI4/oi4-oec-service-go/service/application/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+type driverMock struct {
+	images    map[string][]byte
+	applied   []byte
+	applyErr  error
+	rebooted  bool
+	release   chan struct{}
+	signature []byte
+}
+
+func (d *driverMock) Download(_ context.Context, reference string) ([]byte, error) {
+	if d.release != nil {
+		<-d.release
+	}
+	image, ok := d.images[reference]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return image, nil
+}
+
+func (d *driverMock) Apply(_ context.Context, image []byte, _ string) error {
+	d.applied = image
+	return d.applyErr
+}
+
+func (d *driverMock) Reboot(context.Context) error {
+	d.rebooted = true
+	return nil
+}
+
+type signedDriverMock struct {
+	*driverMock
+}
+
+func (d signedDriverMock) VerifySignature(_ []byte, signature []byte) error {
+	if string(signature) != string(d.signature) {
+		return errors.New("unknown signer")
+	}
+	return nil
+}
+
+type eventRecorder struct {
+	details []Details
+	mutex   sync.Mutex
+}
+
+func (r *eventRecorder) send(event api.Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.details = append(r.details, event.Details.(Details))
+}
+
+func (r *eventRecorder) states() []State {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	states := make([]State, 0, len(r.details))
+	for _, details := range r.details {
+		states = append(states, details.State)
+	}
+	return states
+}
+
+func checksum(image []byte) string {
+	sum := sha256.Sum256(image)
+	return hex.EncodeToString(sum[:])
+}
+
+func newTestUpdater(driver Driver, uploads map[string][]byte) (*Updater, *source.AssetSourceImpl, *eventRecorder) {
+	assetSource := source.NewAssetSourceImpl(api.MasterAssetModel{SoftwareRevision: "1.0.0", RevisionCounter: 3})
+	assetSource.UpdateHealth(api.Health{Health: api.Health_Normal, HealthScore: 100})
+	events := &eventRecorder{}
+	readImage := func(path string) ([]byte, error) {
+		image, ok := uploads[path]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return image, nil
+	}
+	return NewUpdater(assetSource, driver, readImage, events.send), assetSource, events
+}
+
+func TestUpdateFromUpload(t *testing.T) {
+	image := []byte("firmware 2.0.0")
+	driver := &driverMock{}
+	updater, assetSource, events := newTestUpdater(driver, map[string][]byte{"firmware.bin": image})
+
+	methods := make(methodtest.Registry)
+	require.NoError(t, updater.Register(methods))
+
+	_, status := methods.Get(api.ResourceFirmwareUpdate, MethodUpdate).Handler(assetSource, []any{"2.0.0", checksum(image), "firmware.bin", nil, nil})
+	require.Equal(t, api.Status_Good, status)
+	updater.Wait()
+
+	assert.Equal(t, image, driver.applied)
+	assert.True(t, driver.rebooted)
+	assert.Equal(t, []State{StateDownloading, StateVerifying, StateApplying, StateRebooting, StateDone}, events.states())
+
+	mam := assetSource.GetMasterAssetModel()
+	assert.Equal(t, "2.0.0", mam.SoftwareRevision)
+	assert.Equal(t, int32(4), mam.RevisionCounter)
+	assert.Equal(t, api.Health_Normal, assetSource.GetHealth().Health)
+
+	outputArguments, status := methods.Get(api.ResourceFirmwareUpdate, MethodState).Handler(assetSource, nil)
+	assert.Equal(t, api.Status_Good, status)
+	assert.Equal(t, []any{StateDone, byte(100), ""}, outputArguments)
+}
+
+func TestUpdateFromReference(t *testing.T) {
+	image := []byte("firmware 2.0.0")
+	driver := &driverMock{images: map[string][]byte{"https://acme.com/firmware.bin": image}}
+	updater, assetSource, _ := newTestUpdater(driver, nil)
+
+	methods := make(methodtest.Registry)
+	require.NoError(t, updater.Register(methods))
+	update := methods.Get(api.ResourceFirmwareUpdate, MethodUpdate)
+	assert.True(t, update.InputArguments[2].Optional)
+
+	// the path is omitted for a referenced image
+	_, status := update.Handler(assetSource, []any{"2.0.0", checksum(image), nil, "https://acme.com/firmware.bin", nil})
+	require.Equal(t, api.Status_Good, status)
+	updater.Wait()
+
+	assert.Equal(t, image, driver.applied)
+	assert.Equal(t, "2.0.0", assetSource.GetMasterAssetModel().SoftwareRevision)
+}
+
+func TestUpdateFailsOnChecksumMismatch(t *testing.T) {
+	image := []byte("firmware 2.0.0")
+	driver := &driverMock{images: map[string][]byte{"https://example.com/fw": image}}
+	updater, assetSource, events := newTestUpdater(driver, nil)
+
+	require.NoError(t, updater.Start(Request{Version: "2.0.0", Checksum: checksum([]byte("other")), Reference: "https://example.com/fw"}))
+	updater.Wait()
+
+	state, _, err := updater.State()
+	assert.Equal(t, StateFailed, state)
+	assert.EqualError(t, err, "checksum mismatch")
+	assert.Nil(t, driver.applied)
+	assert.Equal(t, []State{StateDownloading, StateVerifying, StateFailed}, events.states())
+	assert.Equal(t, api.Health_MaintenanceRequired, assetSource.GetHealth().Health)
+	assert.Equal(t, "1.0.0", assetSource.GetMasterAssetModel().SoftwareRevision)
+}
+
+func TestUpdateVerifiesSignature(t *testing.T) {
+	image := []byte("firmware 2.0.0")
+	images := map[string][]byte{"https://example.com/fw": image}
+
+	unsigned, _, _ := newTestUpdater(&driverMock{images: images}, nil)
+	require.NoError(t, unsigned.Start(Request{Version: "2.0.0", Checksum: checksum(image), Reference: "https://example.com/fw", Signature: []byte("signed")}))
+	unsigned.Wait()
+	state, _, err := unsigned.State()
+	assert.Equal(t, StateFailed, state)
+	assert.EqualError(t, err, "signed images are not supported by the driver")
+
+	driver := signedDriverMock{&driverMock{images: images, signature: []byte("signed")}}
+	signed, _, _ := newTestUpdater(driver, nil)
+	require.NoError(t, signed.Start(Request{Version: "2.0.0", Checksum: checksum(image), Reference: "https://example.com/fw", Signature: []byte("forged")}))
+	signed.Wait()
+	_, _, err = signed.State()
+	assert.ErrorContains(t, err, "invalid signature")
+
+	require.NoError(t, signed.Start(Request{Version: "2.0.0", Checksum: checksum(image), Reference: "https://example.com/fw", Signature: []byte("signed")}))
+	signed.Wait()
+	state, _, err = signed.State()
+	assert.Equal(t, StateDone, state)
+	assert.NoError(t, err)
+}
+
+func TestUpdateRejectsConcurrentRequests(t *testing.T) {
+	image := []byte("firmware 2.0.0")
+	driver := &driverMock{images: map[string][]byte{"https://example.com/fw": image}, release: make(chan struct{})}
+	updater, assetSource, _ := newTestUpdater(driver, nil)
+
+	methods := make(methodtest.Registry)
+	require.NoError(t, updater.Register(methods))
+	update := methods.Get(api.ResourceFirmwareUpdate, MethodUpdate).Handler
+
+	_, status := update(assetSource, []any{"2.0.0", checksum(image), "", "https://example.com/fw", nil})
+	require.Equal(t, api.Status_Good, status)
+	assert.Equal(t, api.Health_CheckFunction, assetSource.GetHealth().Health)
+
+	_, status = update(assetSource, []any{"2.0.0", checksum(image), "", "https://example.com/fw", nil})
+	assert.Equal(t, api.Status_BadInvalidState, status)
+
+	_, status = update(assetSource, []any{"2.0.0", checksum(image), "", "", nil})
+	assert.Equal(t, api.Status_BadInvalidArgument, status)
+
+	close(driver.release)
+	updater.Wait()
+	state, _, _ := updater.State()
+	assert.Equal(t, StateDone, state)
+}
@@ -0,0 +1,33 @@
+package application
+
+import (
+	"errors"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/firmware"
+)
+
+// RegisterFirmwareUpdate registers the FirmwareUpdate service of the asset. The driver performs the device specific
+// steps, images are either uploaded with the FileUpload service of the application or downloaded by the driver.
+func (asset *AssetImpl) RegisterFirmwareUpdate(driver firmware.Driver) (*firmware.Updater, error) {
+	if driver == nil {
+		return nil, errors.New("a firmware driver is required")
+	}
+
+	updater := firmware.NewUpdater(asset.source, driver, asset.readFirmwareImage, func(event api.Event) {
+		if asset.parent != nil {
+			asset.parent.SendEvent(asset.source.GetOi4Identifier(), event)
+		}
+	})
+	if err := updater.Register(asset); err != nil {
+		return nil, err
+	}
+	return updater, nil
+}
+
+// readFirmwareImage reads an image uploaded to the file transfer sandbox of the application
+func (asset *AssetImpl) readFirmwareImage(path string) ([]byte, error) {
+	if asset.parent == nil || asset.parent.fileTransfer == nil {
+		return nil, errors.New("the file transfer of the application is not enabled")
+	}
+	return asset.parent.fileTransfer.ReadFile(path)
+}
@@ -0,0 +1,316 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/subscription"
+	"github.com/OI4/oi4-oec-service-go/service/opc"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultGetResponseWindow is the time a Get request waits for further responses after the last one, as the number
+// of responding sources and publications is not known in advance
+const DefaultGetResponseWindow = 200 * time.Millisecond
+
+// DefaultGetTimeout limits a Get request with a context without deadline
+const DefaultGetTimeout = 30 * time.Second
+
+var ErrNotStarted = errors.New("the application is not started")
+
+// getCall collects the responses of a Get request and of its follow-up page requests.
+// The responses are queued, as the message handler must not block the client while the request is sent.
+type getCall struct {
+	responses []getResponse
+	mutex     sync.Mutex
+	notify    chan struct{}
+}
+
+type getResponse struct {
+	source         *api.Oi4Identifier
+	networkMessage api.NetworkMessage
+}
+
+func newGetCall() *getCall {
+	return &getCall{notify: make(chan struct{}, 1)}
+}
+
+func (call *getCall) push(response getResponse) {
+	call.mutex.Lock()
+	call.responses = append(call.responses, response)
+	call.mutex.Unlock()
+
+	select {
+	case call.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (call *getCall) take() []getResponse {
+	call.mutex.Lock()
+	defer call.mutex.Unlock()
+
+	responses := call.responses
+	call.responses = nil
+	return responses
+}
+
+// Get requests a resource of another application and returns the DataSetMessages of all responses, without the
+// reserved Pagination and Locale messages. A paginated resource is requested page by page until the last page.
+// A request without source is answered by the application and all its assets.
+// The request ends after no further response was received for the response window, or with the error of the context
+// together with the messages received so far. A context without deadline is limited to DefaultGetTimeout.
+func (app *Oi4ApplicationImpl) Get(ctx context.Context, serviceType api.ServiceType, target api.Oi4Identifier, resource api.ResourceType, source *api.Oi4Identifier, filter *api.Filter) ([]*api.DataSetMessage, error) {
+	if app.mqttClient == nil {
+		return nil, ErrNotStarted
+	}
+	if !tp.SupportsMethod(resource, api.MethodGet) {
+		return nil, fmt.Errorf("resource %s does not support the method %s", resource, api.MethodGet)
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultGetTimeout)
+		defer cancel()
+	}
+
+	responseFilter := tp.NewFilter().ServiceType(serviceType).Oi4Identifier(&target).Method(api.MethodPub).Resource(resource).String()
+	if err := app.subscribeGetResponses(responseFilter); err != nil {
+		return nil, err
+	}
+	defer app.unsubscribeGetResponses(responseFilter)
+
+	call := newGetCall()
+	// requests without response, by their MessageId
+	outstanding := make(map[string]bool)
+	messageIds := make([]string, 0)
+	defer func() {
+		app.getMutex.Lock()
+		defer app.getMutex.Unlock()
+		for _, messageId := range messageIds {
+			delete(app.getCalls, messageId)
+		}
+	}()
+
+	send := func(source *api.Oi4Identifier, pagination api.PaginationRequest) error {
+		messageId := opc.GetMessageID(app.oi4Identifier.ToString())
+		app.getMutex.Lock()
+		app.getCalls[messageId] = call
+		app.getMutex.Unlock()
+		messageIds = append(messageIds, messageId)
+		outstanding[messageId] = true
+
+		topic, message := app.newGetMessage(messageId, serviceType, target, resource, source, filter, pagination)
+		return app.SendGetMessage(topic, message)
+	}
+
+	if err := send(source, api.PaginationRequest{Page: 1}); err != nil {
+		return nil, err
+	}
+
+	result := make([]*api.DataSetMessage, 0)
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-settled:
+			return result, nil
+		case <-call.notify:
+			for _, response := range call.take() {
+				delete(outstanding, *response.networkMessage.CorrelationId)
+
+				messages, next := splitGetResponse(response.networkMessage)
+				result = append(result, messages...)
+				if next == nil {
+					continue
+				}
+				if err := send(response.source, *next); err != nil {
+					return result, err
+				}
+			}
+
+			settled = nil
+			if len(outstanding) == 0 {
+				settled = time.After(app.getResponseWindow)
+			}
+		}
+	}
+}
+
+// GetAs requests a resource like Oi4ApplicationImpl.Get and decodes the payload of every DataSetMessage into T.
+// A resource provided as a list, like the data of all tags, is received as a list per page.
+// The values received before an error of the request are returned together with the error.
+func GetAs[T any](ctx context.Context, app *Oi4ApplicationImpl, serviceType api.ServiceType, target api.Oi4Identifier, resource api.ResourceType, source *api.Oi4Identifier, filter *api.Filter) ([]T, error) {
+	messages, getErr := app.Get(ctx, serviceType, target, resource, source, filter)
+
+	result := make([]T, 0, len(messages))
+	for _, message := range messages {
+		var value T
+		if err := decodePayload(message.Payload, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode %s of %s: %w", resource, message.Source, err)
+		}
+		result = append(result, value)
+	}
+	return result, getErr
+}
+
+// newGetMessage creates the topic and message of a Get request. The topic grammar only allows a filter following the
+// source, so the filter of a request without source is sent in a DataSetMessage.
+func (app *Oi4ApplicationImpl) newGetMessage(messageId string, serviceType api.ServiceType, target api.Oi4Identifier, resource api.ResourceType, source *api.Oi4Identifier, filter *api.Filter, pagination api.PaginationRequest) (string, api.GetMessage) {
+	messages := []any{api.DataSetMessage{DataSetWriterId: api.DataSetWriterIdPaginationRequest, Payload: pagination}}
+
+	topicFilter := filter
+	if source == nil && filter != nil {
+		topicFilter = nil
+		messages = append(messages, api.DataSetMessage{Filter: *filter})
+	}
+
+	topic := tp.NewTopic(serviceType, target, api.MethodGet, resource, source, nil, topicFilter)
+	return topic.ToString(), api.GetMessage{
+		MessageId:      messageId,
+		MessageType:    string(api.UA_DATA),
+		PublisherId:    fmt.Sprintf("%s/%s", app.serviceType, app.oi4Identifier.ToString()),
+		DataSetClassId: resource.ToDataSetClassId(),
+		Messages:       messages,
+	}
+}
+
+// splitGetResponse returns the DataSetMessages of a response without the reserved messages and the request of the
+// next page, if the response is a page with a successor
+func splitGetResponse(networkMessage api.NetworkMessage) ([]*api.DataSetMessage, *api.PaginationRequest) {
+	messages := make([]*api.DataSetMessage, 0, len(networkMessage.Messages))
+	var next *api.PaginationRequest
+
+	for _, message := range networkMessage.Messages {
+		if message == nil {
+			continue
+		}
+
+		switch message.DataSetWriterId {
+		case api.DataSetWriterIdPagination:
+			pagination := api.PaginationResponse{}
+			if err := decodePayload(message.Payload, &pagination); err == nil && pagination.HasNext {
+				next = &api.PaginationRequest{PerPage: pagination.PerPage, Page: pagination.Page + 1, PaginationId: pagination.PaginationId}
+			}
+		case api.DataSetWriterIdPaginationRequest, api.DataSetWriterIdLocale:
+		default:
+			messages = append(messages, message)
+		}
+	}
+
+	return messages, next
+}
+
+// filterHandler is the handler of a topic filter, as the client keeps a single handler per filter. It passes the
+// messages to the subscription of the application with the filter and, while Get requests wait for responses on the
+// filter, the correlated responses to these requests.
+type filterHandler struct {
+	subscription atomic.Pointer[mqtt.MessageHandler]
+	gets         atomic.Int32
+	responses    mqtt.MessageHandler
+}
+
+func (handler *filterHandler) GetHandler() mqtt.MessageHandler {
+	return func(client mqtt.Client, message mqtt.Message) {
+		if subscription := handler.subscription.Load(); subscription != nil {
+			(*subscription)(client, message)
+		}
+		if handler.gets.Load() > 0 {
+			handler.responses(client, message)
+		}
+	}
+}
+
+// filterHandler returns the handler of a filter, the caller holds the filterMutex
+func (app *Oi4ApplicationImpl) filterHandler(filter string) *filterHandler {
+	handler, ok := app.filterHandlers[filter]
+	if !ok {
+		handler = &filterHandler{
+			responses: subscription.NewMessageHandler(app, func(_ api.ResourceType, source *api.Oi4Identifier, networkMessage api.NetworkMessage, _ *tp.Topic) {
+				app.routeGetResponse(source, networkMessage)
+			}, subscription.WithSkipOwnMessage(false)).GetHandler(), // a Get request may be sent to the application itself
+		}
+		app.filterHandlers[filter] = handler
+	}
+	return handler
+}
+
+// subscribe subscribes to the topic of a subscription of the application, pending Get requests on the same filter
+// keep receiving their responses
+func (app *Oi4ApplicationImpl) subscribe(subscription api.Subscription) error {
+	app.filterMutex.Lock()
+	defer app.filterMutex.Unlock()
+
+	handler := app.filterHandler(subscription.GetTopic())
+	messageHandler := subscription.GetHandler().GetHandler()
+	handler.subscription.Store(&messageHandler)
+	return app.mqttClient.SubscribeToTopic(subscription.GetTopic(), subscription.GetQoS(), handler)
+}
+
+// unsubscribe unsubscribes from the topic of a removed subscription, unless Get requests wait for responses on it
+func (app *Oi4ApplicationImpl) unsubscribe(topic string) error {
+	app.filterMutex.Lock()
+	defer app.filterMutex.Unlock()
+
+	if handler, ok := app.filterHandlers[topic]; ok {
+		handler.subscription.Store(nil)
+		if handler.gets.Load() > 0 {
+			return nil
+		}
+		delete(app.filterHandlers, topic)
+	}
+	return app.mqttClient.Unsubscribe(topic)
+}
+
+// subscribeGetResponses subscribes to the responses of Get requests, the subscription is shared by all concurrent
+// requests with the same response filter and by a subscription of the application with this filter
+func (app *Oi4ApplicationImpl) subscribeGetResponses(filter string) error {
+	app.filterMutex.Lock()
+	defer app.filterMutex.Unlock()
+
+	handler := app.filterHandler(filter)
+	if handler.gets.Add(1) > 1 || handler.subscription.Load() != nil {
+		return nil
+	}
+	if err := app.mqttClient.SubscribeToTopic(filter, app.qos, handler); err != nil {
+		handler.gets.Add(-1)
+		delete(app.filterHandlers, filter)
+		return err
+	}
+	return nil
+}
+
+func (app *Oi4ApplicationImpl) unsubscribeGetResponses(filter string) {
+	app.filterMutex.Lock()
+	defer app.filterMutex.Unlock()
+
+	handler, ok := app.filterHandlers[filter]
+	if !ok || handler.gets.Add(-1) > 0 || handler.subscription.Load() != nil {
+		return
+	}
+
+	delete(app.filterHandlers, filter)
+	if err := app.mqttClient.Unsubscribe(filter); err != nil {
+		app.logger.Warnf("Failed to unsubscribe from Get responses %s: %v", filter, err)
+	}
+}
+
+// routeGetResponse passes a response to the Get request it correlates to, other publications are ignored
+func (app *Oi4ApplicationImpl) routeGetResponse(source *api.Oi4Identifier, networkMessage api.NetworkMessage) {
+	if networkMessage.CorrelationId == nil {
+		return
+	}
+
+	app.getMutex.Lock()
+	call, ok := app.getCalls[*networkMessage.CorrelationId]
+	app.getMutex.Unlock()
+
+	if ok {
+		call.push(getResponse{source: source, networkMessage: networkMessage})
+	}
+}
@@ -0,0 +1,58 @@
+package application
+
+import (
+	"encoding/json"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+)
+
+// newGetRequest combines the topic of a Get request with its DataSetMessages.
+// The filter of the topic takes precedence over the filter of the DataSetMessages, the PaginationRequest and the
+// Locale are read from the DataSetMessages with the reserved DataSetWriterIds.
+func newGetRequest(topic *tp.Topic, networkMessage api.NetworkMessage) (api.GetRequest, error) {
+	request := api.GetRequest{
+		MessageId: networkMessage.MessageId,
+		Resource:  topic.Resource,
+		Source:    topic.Source,
+		Filter:    topic.Filter,
+	}
+
+	for _, message := range networkMessage.Messages {
+		if message == nil {
+			continue
+		}
+
+		switch message.DataSetWriterId {
+		case api.DataSetWriterIdPaginationRequest:
+			pagination := &api.PaginationRequest{}
+			if err := decodePayload(message.Payload, pagination); err != nil {
+				return request, err
+			}
+			request.Pagination = pagination
+		case api.DataSetWriterIdLocale:
+			locale := &api.Locale{}
+			if err := decodePayload(message.Payload, locale); err != nil {
+				return request, err
+			}
+			if locale.Locale != "" {
+				request.Locale = locale
+			}
+		default:
+			if request.Filter == nil && message.Filter != "" {
+				filter := message.Filter
+				request.Filter = &filter
+			}
+		}
+	}
+
+	return request, nil
+}
+
+// decodePayload converts the generic payload of a decoded DataSetMessage into its type
+func decodePayload(payload any, v any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
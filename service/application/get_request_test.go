@@ -0,0 +1,42 @@
+package application
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewGetRequest(t *testing.T) {
+	topic, err := tp.ParseTopic("Oi4/Utility/acme.com///1/Get/Data/acme.com///1")
+	require.NoError(t, err)
+
+	request, err := newGetRequest(topic, api.NetworkMessage{
+		MessageId: "1",
+		Messages: []*api.DataSetMessage{
+			{DataSetWriterId: 10, Filter: "tag1"},
+			{DataSetWriterId: api.DataSetWriterIdPaginationRequest, Payload: map[string]any{"PerPage": 20, "Page": 3}},
+			{DataSetWriterId: api.DataSetWriterIdLocale, Payload: map[string]any{"Locale": "de-DE"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1", request.MessageId)
+	assert.Equal(t, api.ResourceData, request.Resource)
+	assert.Equal(t, "acme.com///1", request.Source.ToString())
+	assert.Equal(t, "tag1", request.Filter.String())
+	assert.Equal(t, &api.PaginationRequest{PerPage: 20, Page: 3}, request.Pagination)
+	assert.Equal(t, &api.Locale{Locale: "de-DE"}, request.Locale)
+
+	// the filter of the topic takes precedence
+	topic, err = tp.ParseTopic("Oi4/Utility/acme.com///1/Get/Data/acme.com///1/tag2")
+	require.NoError(t, err)
+	request, err = newGetRequest(topic, api.NetworkMessage{Messages: []*api.DataSetMessage{{DataSetWriterId: 10, Filter: "tag1"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "tag2", request.Filter.String())
+	assert.Nil(t, request.Pagination)
+	assert.Nil(t, request.Locale)
+
+	_, err = newGetRequest(topic, api.NetworkMessage{Messages: []*api.DataSetMessage{{DataSetWriterId: api.DataSetWriterIdPaginationRequest, Payload: "page 1"}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,19 @@
+// Package methodtest provides a method registry for the tests of the services, which are used without application
+package methodtest
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+)
+
+// Registry is an api.MethodRegistry keeping the methods by service and method id
+type Registry map[string]api.Method
+
+func (registry Registry) RegisterMethod(service api.ResourceType, methodId string, method api.Method) error {
+	registry[string(service)+"/"+methodId] = method
+	return nil
+}
+
+// Get returns the method of a service, the zero Method if it is not registered
+func (registry Registry) Get(service api.ResourceType, methodId string) api.Method {
+	return registry[string(service)+"/"+methodId]
+}
@@ -0,0 +1,195 @@
+package application
+
+import (
+	"fmt"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/subscription"
+	"github.com/OI4/oi4-oec-service-go/service/opc"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+	"reflect"
+	"sync"
+)
+
+type methodKey struct {
+	service  api.ResourceType
+	methodId string
+}
+
+// methodRegistry contains the methods of the application or of an asset by service and MethodId
+type methodRegistry struct {
+	methods map[methodKey]api.Method
+	mutex   sync.RWMutex
+}
+
+func newMethodRegistry() *methodRegistry {
+	return &methodRegistry{methods: make(map[methodKey]api.Method)}
+}
+
+func (registry *methodRegistry) register(service api.ResourceType, methodId string, method api.Method) error {
+	if !tp.SupportsMethod(service, api.MethodCall) {
+		return fmt.Errorf("the service %s can not be called", service)
+	}
+	if method.Handler == nil {
+		return fmt.Errorf("the method %s of the service %s has no handler", methodId, service)
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.methods[methodKey{service: service, methodId: methodId}] = method
+	return nil
+}
+
+func (registry *methodRegistry) get(service api.ResourceType, methodId string) (api.Method, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	method, ok := registry.methods[methodKey{service: service, methodId: methodId}]
+	return method, ok
+}
+
+// supports returns whether a method of the service is registered
+func (registry *methodRegistry) supports(service api.ResourceType) bool {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	for key := range registry.methods {
+		if key.service == service {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterMethod registers a method of a service of the application, which is called by Call requests for the
+// application source
+func (app *Oi4ApplicationImpl) RegisterMethod(service api.ResourceType, methodId string, method api.Method) error {
+	return app.methods.register(service, methodId, method)
+}
+
+// RegisterMethod registers a method of a service of the asset, which is called by Call requests for the asset
+func (asset *AssetImpl) RegisterMethod(service api.ResourceType, methodId string, method api.Method) error {
+	return asset.methods.register(service, methodId, method)
+}
+
+// CallHandler handles the Call requests of the application and its assets. Every method of the request is called
+// with its validated input arguments, the results are published as Reply with the CorrelationId of the request.
+func (app *Oi4ApplicationImpl) CallHandler() api.MessageHandler {
+	return subscription.NewServiceMessageHandler(app, func(service api.ResourceType, source *api.Oi4Identifier, serviceMessage api.ServiceNetworkMessage, topic *tp.Topic) {
+		baseSource := app.getSource(source)
+		registry := app.getMethodRegistry(source)
+		if baseSource == nil || registry == nil {
+			app.logger.Infof("Ignoring Call request %s for unknown source on topic %s", serviceMessage.MessageId, topic.ToString())
+			return
+		}
+
+		request := api.ServiceParameterRequest{}
+		if err := decodePayload(serviceMessage.Message, &request); err != nil {
+			app.logger.Infof("Invalid Call request %s on topic %s: %v", serviceMessage.MessageId, topic.ToString(), err)
+			return
+		}
+
+		supported := registry.supports(service)
+		results := make([]api.CallMethodResult, 0, len(request.MethodsToCall))
+		for _, methodToCall := range request.MethodsToCall {
+			if !supported {
+				results = append(results, api.CallMethodResult{StatusCode: api.Status_BadServiceUnsupported})
+				continue
+			}
+			method, ok := registry.get(service, methodToCall.MethodId)
+			if !ok {
+				results = append(results, api.CallMethodResult{StatusCode: api.Status_BadMethodInvalid})
+				continue
+			}
+			results = append(results, callMethod(method, baseSource, methodToCall))
+		}
+
+		app.sendReply(service, source, serviceMessage.MessageId, api.ServiceParametersResponse{Results: results})
+	}, subscription.WithSkipOwnMessage(false)) // the topic of a Call request contains the requested application
+}
+
+// subscribeCall subscribes to the Call requests of the application and its assets
+func (app *Oi4ApplicationImpl) subscribeCall() error {
+	filter := tp.NewFilter().ServiceType(app.serviceType).Oi4Identifier(app.oi4Identifier).Method(api.MethodCall)
+	return app.mqttClient.Subscribe(subscription.NewTopicSubscription(filter, app.CallHandler()))
+}
+
+// sendReply publishes the response of a Call request on the Reply topic of the service
+func (app *Oi4ApplicationImpl) sendReply(service api.ResourceType, source *api.Oi4Identifier, correlationId string, response api.ServiceParametersResponse) {
+	topic := tp.NewTopic(app.serviceType, *app.oi4Identifier, api.MethodReply, service, source, nil, nil)
+	message := api.ServiceNetworkMessage{
+		MessageId:      opc.GetMessageID(app.oi4Identifier.ToString()),
+		MessageType:    api.MSG,
+		PublisherId:    fmt.Sprintf("%s/%s", app.serviceType, app.oi4Identifier.ToString()),
+		DataSetClassId: service.ToDataSetClassId(),
+		CorrelationId:  correlationId,
+		Message:        response,
+	}
+
+	if err := app.mqttClient.PublishResource(topic.ToString(), app.qos, false, message); err != nil {
+		app.logger.Warnf("Failed to publish reply to topic %s: %v", topic.ToString(), err)
+	}
+}
+
+// getMethodRegistry returns the methods of the application for nil or its own identifier, otherwise of the asset
+func (app *Oi4ApplicationImpl) getMethodRegistry(source *api.Oi4Identifier) *methodRegistry {
+	if source == nil || source.Equals(app.oi4Identifier) {
+		return app.methods
+	}
+
+	app.assetMutex.RLock()
+	defer app.assetMutex.RUnlock()
+	if asset, ok := app.assets[*source]; ok {
+		return asset.methods
+	}
+	return nil
+}
+
+// callMethod validates the input arguments and calls the handler of the method, if all arguments are valid
+func callMethod(method api.Method, source api.BaseSource, methodToCall api.CallMethodRequest) api.CallMethodResult {
+	inputArguments, inputArgumentResults, status := decodeInputArguments(method.InputArguments, methodToCall.InputArguments)
+	if status != api.Status_Good {
+		return api.CallMethodResult{StatusCode: status, InputArgumentResults: inputArgumentResults}
+	}
+
+	outputArguments, status := method.Handler(source, inputArguments)
+	return api.CallMethodResult{
+		StatusCode:           status,
+		InputArgumentResults: inputArgumentResults,
+		OutputArguments:      outputArguments,
+	}
+}
+
+// decodeInputArguments decodes the values into the types of the arguments. The result of every value is reported,
+// the status is BadInvalidArgument if a value has the wrong type.
+func decodeInputArguments(arguments []api.MethodArgument, values []any) ([]any, []api.StatusCode, api.StatusCode) {
+	if len(values) > len(arguments) {
+		return nil, nil, api.Status_BadTooManyArguments
+	}
+
+	inputArguments := make([]any, len(arguments))
+	results := make([]api.StatusCode, len(values))
+	status := api.Status_Good
+	for i, argument := range arguments {
+		if i >= len(values) {
+			if !argument.Optional {
+				return nil, results, api.Status_BadArgumentsMissing
+			}
+			continue
+		}
+
+		value := reflect.New(argument.Type)
+		if err := decodePayload(values[i], value.Interface()); err != nil {
+			results[i] = api.Status_BadTypeMismatch
+			status = api.Status_BadInvalidArgument
+			continue
+		}
+		results[i] = api.Status_Good
+		inputArguments[i] = value.Elem().Interface()
+	}
+
+	if status != api.Status_Good {
+		return nil, results, status
+	}
+	return inputArguments, results, status
+}
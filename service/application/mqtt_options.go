@@ -0,0 +1,93 @@
+package application
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/container"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// newMqttClientOptions creates the client options from the container storage.
+// Username and password are taken from the secret storage, the mTLS client certificate from the OI4 certificate storage
+// together with the private key and passphrase from the secret storage. The broker is verified with the CAs of the
+// message bus storage or, if not present, with the global CAs of the OI4 certificate storage.
+func newMqttClientOptions(storage container.Storage, clientId string) *api.MqttClientOptions {
+	brokerConfig := storage.MessageBusStorage.BrokerConfiguration
+	options := &api.MqttClientOptions{
+		Host:      brokerConfig.Address,
+		Port:      int(brokerConfig.SecurePort),
+		Transport: api.Transport(brokerConfig.Transport),
+		Path:      brokerConfig.Path,
+		Tls:       true,
+		ClientId:  clientId,
+	}
+	for _, endpoint := range brokerConfig.Endpoints {
+		options.FailoverEndpoints = append(options.FailoverEndpoints, api.BrokerEndpoint{
+			Host:      endpoint.Address,
+			Port:      int(endpoint.SecurePort),
+			Transport: api.Transport(endpoint.Transport),
+			Path:      endpoint.Path,
+		})
+	}
+
+	if secrets := storage.SecretStorage; secrets != nil {
+		if secrets.MqttCredentials != nil {
+			options.Username = secrets.MqttCredentials.Username()
+			options.Password, _ = secrets.MqttCredentials.Password()
+		}
+		if secrets.MqttPrivateKey != nil {
+			options.Client_private_key_pem = string(pem.EncodeToMemory(secrets.MqttPrivateKey))
+		}
+		if secrets.MqttPassphrase != nil {
+			options.Client_private_key_passphrase = *secrets.MqttPassphrase
+		}
+	}
+
+	certificates := storage.Oi4CertificateStorage
+	if certificates != nil && certificates.ClientCertificate != nil {
+		// the sub CAs are sent along with the client certificate, so the broker can build the chain to its root CA
+		chain := append([]*x509.Certificate{certificates.ClientCertificate}, sortedCertificates(certificates.SubCaCertificates)...)
+		options.Client_certificate_pem = encodeCertificates(chain...)
+	}
+
+	caCertificates := caCertificatesOf(storage.MessageBusStorage.RootCaCertificate, storage.MessageBusStorage.SubCaCertificates)
+	if len(caCertificates) == 0 && certificates != nil {
+		caCertificates = caCertificatesOf(certificates.RootCaCertificate, certificates.SubCaCertificates)
+	}
+	if storage.MessageBusStorage.BrokerCertificate != nil {
+		caCertificates = append(caCertificates, storage.MessageBusStorage.BrokerCertificate)
+	}
+	if len(caCertificates) > 0 {
+		options.Ca_certificate_pem = encodeCertificates(caCertificates...)
+		options.TlsVerify = true
+	}
+
+	return options
+}
+
+func caCertificatesOf(root *x509.Certificate, subCas map[string]*x509.Certificate) []*x509.Certificate {
+	result := make([]*x509.Certificate, 0)
+	if root != nil {
+		result = append(result, root)
+	}
+	return append(result, sortedCertificates(subCas)...)
+}
+
+func sortedCertificates(certificates map[string]*x509.Certificate) []*x509.Certificate {
+	result := make([]*x509.Certificate, 0, len(certificates))
+	for _, name := range slices.Sorted(maps.Keys(certificates)) {
+		result = append(result, certificates[name])
+	}
+	return result
+}
+
+func encodeCertificates(certificates ...*x509.Certificate) string {
+	var builder strings.Builder
+	for _, certificate := range certificates {
+		builder.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}))
+	}
+	return builder.String()
+}
@@ -0,0 +1,143 @@
+package application
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/container"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func testCertificate(name string) *x509.Certificate {
+	return &x509.Certificate{Raw: []byte(name)}
+}
+
+// decodeCertificates returns the names of the test certificates in the order of the PEM blocks, nil if there are none
+func decodeCertificates(encoded string) []string {
+	var names []string
+	rest := []byte(encoded)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return names
+		}
+		names = append(names, string(block.Bytes))
+	}
+}
+
+func TestNewMqttClientOptions(t *testing.T) {
+	passphrase := "passphrase"
+	privateKey := &pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}
+	brokerConfiguration := &container.BrokerConfiguration{
+		Address:    "broker",
+		SecurePort: 8883,
+		Transport:  "ws",
+		Path:       "/mqtt",
+		Endpoints: []container.BrokerEndpoint{
+			{Address: "failover1", SecurePort: 8884},
+			{Address: "failover2", SecurePort: 443, Transport: "ws", Path: "/ws"},
+		},
+	}
+	subCas := map[string]*x509.Certificate{
+		"b.pem": testCertificate("sub-b"),
+		"a.pem": testCertificate("sub-a"),
+	}
+
+	tests := []struct {
+		name                 string
+		messageBusStorage    *container.MessageBusStorage
+		certificateStorage   *container.Oi4CertificateStorage
+		secretStorage        *container.SecretStorage
+		clientCertificates   []string
+		caCertificates       []string
+		tlsVerify            bool
+		username             string
+		password             string
+		privateKey           string
+		privateKeyPassphrase string
+	}{
+		{
+			name:              "anonymous without CAs",
+			messageBusStorage: &container.MessageBusStorage{},
+			tlsVerify:         false,
+		},
+		{
+			name:              "credentials",
+			messageBusStorage: &container.MessageBusStorage{},
+			secretStorage:     &container.SecretStorage{MqttCredentials: url.UserPassword("user", "secret")},
+			username:          "user",
+			password:          "secret",
+		},
+		{
+			name:                 "client certificate with sorted sub CAs",
+			messageBusStorage:    &container.MessageBusStorage{},
+			certificateStorage:   &container.Oi4CertificateStorage{ClientCertificate: testCertificate("client"), SubCaCertificates: subCas},
+			secretStorage:        &container.SecretStorage{MqttPrivateKey: privateKey, MqttPassphrase: &passphrase},
+			clientCertificates:   []string{"client", "sub-a", "sub-b"},
+			caCertificates:       []string{"sub-a", "sub-b"},
+			tlsVerify:            true,
+			privateKey:           string(pem.EncodeToMemory(privateKey)),
+			privateKeyPassphrase: passphrase,
+		},
+		{
+			name:               "CAs of the message bus storage",
+			messageBusStorage:  &container.MessageBusStorage{RootCaCertificate: testCertificate("bus-root"), SubCaCertificates: map[string]*x509.Certificate{"sub.pem": testCertificate("bus-sub")}},
+			certificateStorage: &container.Oi4CertificateStorage{RootCaCertificate: testCertificate("root"), SubCaCertificates: subCas},
+			caCertificates:     []string{"bus-root", "bus-sub"},
+			tlsVerify:          true,
+		},
+		{
+			name:               "fallback to the CAs of the OI4 certificate storage",
+			messageBusStorage:  &container.MessageBusStorage{},
+			certificateStorage: &container.Oi4CertificateStorage{RootCaCertificate: testCertificate("root"), SubCaCertificates: subCas},
+			caCertificates:     []string{"root", "sub-a", "sub-b"},
+			tlsVerify:          true,
+		},
+		{
+			name:               "broker certificate appended to the CAs",
+			messageBusStorage:  &container.MessageBusStorage{BrokerCertificate: testCertificate("broker")},
+			certificateStorage: &container.Oi4CertificateStorage{RootCaCertificate: testCertificate("root")},
+			caCertificates:     []string{"root", "broker"},
+			tlsVerify:          true,
+		},
+		{
+			name:              "broker certificate only",
+			messageBusStorage: &container.MessageBusStorage{BrokerCertificate: testCertificate("broker")},
+			caCertificates:    []string{"broker"},
+			tlsVerify:         true,
+		},
+	}
+
+	for _, test := range tests {
+		test.messageBusStorage.BrokerConfiguration = brokerConfiguration
+		storage := container.Storage{
+			MessageBusStorage:     test.messageBusStorage,
+			Oi4CertificateStorage: test.certificateStorage,
+			SecretStorage:         test.secretStorage,
+		}
+
+		options := newMqttClientOptions(storage, "client-id")
+
+		assert.Equal(t, "broker", options.Host, test.name)
+		assert.Equal(t, 8883, options.Port, test.name)
+		assert.Equal(t, api.Transport("ws"), options.Transport, test.name)
+		assert.Equal(t, "/mqtt", options.Path, test.name)
+		assert.True(t, options.Tls, test.name)
+		assert.Equal(t, "client-id", options.ClientId, test.name)
+		assert.Equal(t, []api.BrokerEndpoint{
+			{Host: "failover1", Port: 8884},
+			{Host: "failover2", Port: 443, Transport: api.Transport("ws"), Path: "/ws"},
+		}, options.FailoverEndpoints, test.name)
+
+		assert.Equal(t, test.username, options.Username, test.name)
+		assert.Equal(t, test.password, options.Password, test.name)
+		assert.Equal(t, test.privateKey, options.Client_private_key_pem, test.name)
+		assert.Equal(t, test.privateKeyPassphrase, options.Client_private_key_passphrase, test.name)
+		assert.Equal(t, test.clientCertificates, decodeCertificates(options.Client_certificate_pem), test.name)
+		assert.Equal(t, test.caCertificates, decodeCertificates(options.Ca_certificate_pem), test.name)
+		assert.Equal(t, test.tlsVerify, options.TlsVerify, test.name)
+	}
+}
@@ -0,0 +1,250 @@
+// Package outbox persists publications on disk while the broker is not reachable and replays them after the reconnect.
+// Every message is stored in its own file, named by a sequence number, so the publication order survives a restart.
+package outbox
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fileExtension = ".json"
+
+var ErrMessageTooLarge = errors.New("message exceeds the size limit of the outbox")
+
+// Config limits the outbox, a zero value disables the limit
+type Config struct {
+	// MaxSize is the maximum size of all stored messages in bytes, the oldest messages are dropped on overflow
+	MaxSize int64
+	// MaxAge is the maximum age of a stored message, older messages are dropped instead of replayed
+	MaxAge time.Duration
+}
+
+// Entry is a stored publication, the NetworkMessage keeps its MessageId and timestamps for the replay
+type Entry struct {
+	Topic    string
+	Qos      byte
+	Retained bool
+	// MessageExpiry of the publication with MQTT 5, 0 if it does not expire
+	MessageExpiry time.Duration
+	Stored        time.Time
+	Message       *api.NetworkMessage
+}
+
+type file struct {
+	sequence uint64
+	size     int64
+	stored   time.Time
+}
+
+type Outbox struct {
+	dir    string
+	config Config
+
+	files        []file
+	size         int64
+	nextSequence uint64
+	dropped      uint64
+	mutex        sync.Mutex
+
+	now func() time.Time
+}
+
+// Open creates the outbox directory if needed and loads the messages stored by a previous run
+func Open(dir string, config Config) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox directory: %w", err)
+	}
+
+	outbox := &Outbox{
+		dir:    dir,
+		config: config,
+		files:  make([]file, 0),
+		now:    time.Now,
+	}
+
+	for _, dirEntry := range dirEntries {
+		sequence, ok := parseFileName(dirEntry.Name())
+		if !ok || dirEntry.IsDir() {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read outbox file %s: %w", dirEntry.Name(), err)
+		}
+		outbox.files = append(outbox.files, file{sequence: sequence, size: info.Size(), stored: info.ModTime()})
+		outbox.size += info.Size()
+		outbox.nextSequence = max(outbox.nextSequence, sequence+1)
+	}
+	slices.SortFunc(outbox.files, func(a, b file) int {
+		return cmp.Compare(a.sequence, b.sequence)
+	})
+
+	return outbox, nil
+}
+
+// Add stores a message behind all others and returns the number of messages dropped to stay within the limits
+func (o *Outbox) Add(entry Entry) (int, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	entry.Stored = o.now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	size := int64(len(data))
+	if o.config.MaxSize > 0 && size > o.config.MaxSize {
+		o.dropped++
+		return 1, ErrMessageTooLarge
+	}
+
+	dropped := o.dropExpired()
+	for o.config.MaxSize > 0 && len(o.files) > 0 && o.size+size > o.config.MaxSize {
+		o.removeHead()
+		dropped++
+	}
+	o.dropped += uint64(dropped)
+
+	sequence := o.nextSequence
+	if err = o.write(sequence, data); err != nil {
+		return dropped, err
+	}
+	o.nextSequence++
+	o.files = append(o.files, file{sequence: sequence, size: size, stored: entry.Stored})
+	o.size += size
+
+	return dropped, nil
+}
+
+// Replay publishes the stored messages in order and removes every published message.
+// It stops at the first failed publication, the message is kept for the next replay.
+// Messages added during the replay are replayed as well.
+func (o *Outbox) Replay(publish func(entry Entry) error) error {
+	for {
+		entry, sequence, ok, err := o.head()
+		if err != nil || !ok {
+			return err
+		}
+
+		if err = publish(entry); err != nil {
+			return err
+		}
+		o.remove(sequence)
+	}
+}
+
+// Len returns the number of stored messages
+func (o *Outbox) Len() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return len(o.files)
+}
+
+// Dropped returns the number of messages dropped because of the size or age limit
+func (o *Outbox) Dropped() uint64 {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return o.dropped
+}
+
+// head reads the oldest message which is not expired
+func (o *Outbox) head() (Entry, uint64, bool, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for {
+		o.dropped += uint64(o.dropExpired())
+		if len(o.files) == 0 {
+			return Entry{}, 0, false, nil
+		}
+
+		sequence := o.files[0].sequence
+		data, err := os.ReadFile(o.path(sequence))
+		if err != nil {
+			return Entry{}, 0, false, fmt.Errorf("failed to read outbox message: %w", err)
+		}
+
+		entry := Entry{}
+		if err = json.Unmarshal(data, &entry); err == nil {
+			return entry, sequence, true, nil
+		}
+
+		// a corrupted message, e.g. after a power loss, must not block the outbox
+		o.removeHead()
+		o.dropped++
+	}
+}
+
+// remove deletes a replayed message, unless it was already dropped in the meantime
+func (o *Outbox) remove(sequence uint64) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if len(o.files) > 0 && o.files[0].sequence == sequence {
+		o.removeHead()
+	}
+}
+
+func (o *Outbox) dropExpired() int {
+	if o.config.MaxAge <= 0 {
+		return 0
+	}
+
+	dropped := 0
+	expired := o.now().Add(-o.config.MaxAge)
+	for len(o.files) > 0 && o.files[0].stored.Before(expired) {
+		o.removeHead()
+		dropped++
+	}
+	return dropped
+}
+
+func (o *Outbox) removeHead() {
+	head := o.files[0]
+	_ = os.Remove(o.path(head.sequence))
+	o.files = o.files[1:]
+	o.size -= head.size
+}
+
+// write stores the message in a temporary file first, so a partially written message is never replayed
+func (o *Outbox) write(sequence uint64, data []byte) error {
+	path := o.path(sequence)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("failed to write outbox message: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write outbox message: %w", err)
+	}
+	return nil
+}
+
+func (o *Outbox) path(sequence uint64) string {
+	return filepath.Join(o.dir, fmt.Sprintf("%020d%s", sequence, fileExtension))
+}
+
+func parseFileName(name string) (uint64, bool) {
+	if !strings.HasSuffix(name, fileExtension) {
+		return 0, false
+	}
+	sequence, err := strconv.ParseUint(strings.TrimSuffix(name, fileExtension), 10, 64)
+	return sequence, err == nil
+}
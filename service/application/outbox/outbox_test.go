@@ -0,0 +1,134 @@
+package outbox
+
+import (
+	"errors"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+func testEntry(messageId string) Entry {
+	return Entry{
+		Topic: "Oi4/Utility/acme.com///1/Pub/Data",
+		Qos:   1,
+		Message: &api.NetworkMessage{
+			MessageId:   messageId,
+			MessageType: api.UA_DATA,
+			PublisherId: "Utility/acme.com///1",
+			Messages:    []*api.DataSetMessage{{DataSetWriterId: 10, Payload: map[string]any{"Pv": 1.0}}},
+		},
+	}
+}
+
+func replayedMessageIds(t *testing.T, outbox *Outbox) []string {
+	messageIds := make([]string, 0)
+	require.NoError(t, outbox.Replay(func(entry Entry) error {
+		messageIds = append(messageIds, entry.Message.MessageId)
+		return nil
+	}))
+	return messageIds
+}
+
+func TestOutboxReplaysInOrderAfterReopen(t *testing.T) {
+	dir := t.TempDir()
+	outbox, err := Open(dir, Config{})
+	require.NoError(t, err)
+
+	for _, messageId := range []string{"1", "2", "3"} {
+		_, err = outbox.Add(testEntry(messageId))
+		require.NoError(t, err)
+	}
+
+	reopened, err := Open(dir, Config{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, reopened.Len())
+
+	_, err = reopened.Add(testEntry("4"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"1", "2", "3", "4"}, replayedMessageIds(t, reopened))
+	assert.Equal(t, 0, reopened.Len())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestOutboxReplayStopsOnError(t *testing.T) {
+	outbox, err := Open(t.TempDir(), Config{})
+	require.NoError(t, err)
+	_, _ = outbox.Add(testEntry("1"))
+	_, _ = outbox.Add(testEntry("2"))
+
+	errOffline := errors.New("offline")
+	err = outbox.Replay(func(entry Entry) error {
+		return errOffline
+	})
+	assert.ErrorIs(t, err, errOffline)
+	assert.Equal(t, []string{"1", "2"}, replayedMessageIds(t, outbox))
+}
+
+func TestOutboxSizeLimitDropsOldest(t *testing.T) {
+	// a fixed time keeps the size of all entries equal
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir := t.TempDir()
+	outbox, err := Open(dir, Config{})
+	require.NoError(t, err)
+	outbox.now = func() time.Time { return now }
+	_, _ = outbox.Add(testEntry("1"))
+	size := outbox.size
+
+	limited, err := Open(dir, Config{MaxSize: 2 * size})
+	require.NoError(t, err)
+	limited.now = func() time.Time { return now }
+
+	dropped, err := limited.Add(testEntry("2"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+
+	dropped, err = limited.Add(testEntry("3"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, dropped)
+	assert.Equal(t, uint64(1), limited.Dropped())
+
+	assert.Equal(t, []string{"2", "3"}, replayedMessageIds(t, limited))
+}
+
+func TestOutboxRejectsMessageLargerThanLimit(t *testing.T) {
+	outbox, err := Open(t.TempDir(), Config{MaxSize: 10})
+	require.NoError(t, err)
+
+	_, err = outbox.Add(testEntry("1"))
+	assert.ErrorIs(t, err, ErrMessageTooLarge)
+	assert.Equal(t, 0, outbox.Len())
+}
+
+func TestOutboxAgeLimit(t *testing.T) {
+	outbox, err := Open(t.TempDir(), Config{MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	now := time.Now()
+	outbox.now = func() time.Time { return now }
+	_, _ = outbox.Add(testEntry("1"))
+
+	now = now.Add(30 * time.Minute)
+	_, _ = outbox.Add(testEntry("2"))
+
+	now = now.Add(31 * time.Minute)
+	assert.Equal(t, []string{"2"}, replayedMessageIds(t, outbox))
+	assert.Equal(t, uint64(1), outbox.Dropped())
+}
+
+func TestOutboxSkipsCorruptedMessage(t *testing.T) {
+	outbox, err := Open(t.TempDir(), Config{})
+	require.NoError(t, err)
+	_, _ = outbox.Add(testEntry("1"))
+	_, _ = outbox.Add(testEntry("2"))
+	require.NoError(t, os.WriteFile(outbox.path(0), []byte("{"), 0o640))
+
+	assert.Equal(t, []string{"2"}, replayedMessageIds(t, outbox))
+}
@@ -0,0 +1,204 @@
+// Package pagination splits large resources into pages. A Get request with a PaginationRequest receives the requested
+// page only, the snapshot of the resource is kept for a short time, so the following pages are consistent with it.
+package pagination
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/google/uuid"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPageSize is the number of elements of a page if the request does not define it
+	DefaultPageSize = 100
+	// DefaultCursorTtl is the time a snapshot is kept after the last page request
+	DefaultCursorTtl = time.Minute
+)
+
+// cursor is the snapshot of a paginated resource
+type cursor struct {
+	elements []any
+	// list is set if the resource is provided as a single list, the pages are lists of the same type
+	list    *reflect.Value
+	locale  *api.Locale
+	expires time.Time
+}
+
+// cursorKey identifies the snapshot of a publication, the application shares a paginator for all publications
+type cursorKey struct {
+	publication string
+	id          string
+}
+
+type Paginator struct {
+	pageSize  int
+	cursorTtl time.Duration
+
+	cursors map[cursorKey]*cursor
+	mutex   sync.Mutex
+
+	now func() time.Time
+}
+
+// NewPaginator creates a paginator with the default page size, 0 disables the pagination of resources which are not
+// explicitly requested with a PaginationRequest
+func NewPaginator(pageSize int, cursorTtl time.Duration) *Paginator {
+	if cursorTtl <= 0 {
+		cursorTtl = DefaultCursorTtl
+	}
+	return &Paginator{
+		pageSize:  pageSize,
+		cursorTtl: cursorTtl,
+		cursors:   make(map[cursorKey]*cursor),
+		now:       time.Now,
+	}
+}
+
+// Paginate returns the page requested by a PaginationRequest, otherwise all pages of the resource.
+// A resource fitting into a single page without PaginationRequest is returned without pagination.
+// A PaginationId of another publication returns no page, as a Get request is answered by several publications.
+func (p *Paginator) Paginate(publication string, request *api.GetRequest, get func() ([]any, *api.Locale)) []api.Page {
+	if request == nil || request.Pagination == nil {
+		content, locale := get()
+		c := newCursor(content, locale)
+		if p.pageSize <= 0 || c.len() <= p.pageSize {
+			return []api.Page{{Content: content, Locale: locale}}
+		}
+		return c.pages(uuid.NewString(), p.pageSize)
+	}
+
+	pagination := *request.Pagination
+	perPage := int(pagination.PerPage)
+	if perPage == 0 {
+		perPage = max(p.pageSize, 0)
+	}
+
+	key := cursorKey{publication: publication, id: pagination.PaginationId}
+	c, foreign := p.cursor(key)
+	if foreign {
+		return nil
+	}
+	if c == nil {
+		content, locale := get()
+		c = newCursor(content, locale)
+		key.id = uuid.NewString()
+	}
+
+	page := c.page(key.id, max(int(pagination.Page), 1), perPage)
+	if page.Pagination.HasNext {
+		p.store(key, c)
+	} else {
+		p.remove(key)
+	}
+	return []api.Page{page}
+}
+
+// cursor returns the snapshot of a previous request and removes expired snapshots.
+// foreign is true if the id belongs to the snapshot of another publication.
+func (p *Paginator) cursor(key cursorKey) (c *cursor, foreign bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	for k, existing := range p.cursors {
+		if now.After(existing.expires) {
+			delete(p.cursors, k)
+		}
+	}
+	if key.id == "" {
+		return nil, false
+	}
+	if existing, ok := p.cursors[key]; ok {
+		return existing, false
+	}
+	for k := range p.cursors {
+		if k.id == key.id {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+func (p *Paginator) store(key cursorKey, c *cursor) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	c.expires = p.now().Add(p.cursorTtl)
+	p.cursors[key] = c
+}
+
+func (p *Paginator) remove(key cursorKey) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.cursors, key)
+}
+
+// Len returns the number of snapshots kept for following page requests
+func (p *Paginator) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return len(p.cursors)
+}
+
+// newCursor creates a snapshot, a resource provided as a single list, like the data of all tags, is paginated by the
+// elements of the list
+func newCursor(content []any, locale *api.Locale) *cursor {
+	if len(content) == 1 && content[0] != nil {
+		if list := reflect.ValueOf(content[0]); list.Kind() == reflect.Slice {
+			return &cursor{list: &list, locale: locale}
+		}
+	}
+	return &cursor{elements: content, locale: locale}
+}
+
+func (c *cursor) len() int {
+	if c.list != nil {
+		return c.list.Len()
+	}
+	return len(c.elements)
+}
+
+func (c *cursor) slice(start int, end int) []any {
+	if c.list != nil {
+		return []any{c.list.Slice(start, end).Interface()}
+	}
+	return c.elements[start:end]
+}
+
+// page returns a page, the first page is 1. A perPage of 0 returns all elements.
+func (c *cursor) page(id string, page int, perPage int) api.Page {
+	total := c.len()
+	if perPage <= 0 {
+		perPage = max(total, 1)
+	}
+
+	start := min((page-1)*perPage, total)
+	end := min(start+perPage, total)
+
+	return api.Page{
+		Content: c.slice(start, end),
+		Locale:  c.locale,
+		Pagination: &api.PaginationResponse{
+			TotalCount:   uint32(total),
+			PerPage:      uint32(perPage),
+			Page:         uint32(page),
+			HasNext:      end < total,
+			PaginationId: id,
+		},
+	}
+}
+
+func (c *cursor) pages(id string, perPage int) []api.Page {
+	pages := make([]api.Page, 0)
+	for page := 1; ; page++ {
+		current := c.page(id, page, perPage)
+		pages = append(pages, current)
+		if !current.Pagination.HasNext {
+			return pages
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package pagination
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func elements(count int) func() ([]any, *api.Locale) {
+	return func() ([]any, *api.Locale) {
+		result := make([]any, count)
+		for i := range result {
+			result[i] = i
+		}
+		return result, nil
+	}
+}
+
+func TestPaginateSmallResourceWithoutPagination(t *testing.T) {
+	paginator := NewPaginator(10, time.Minute)
+
+	pages := paginator.Paginate("MAM/acme.com///1", nil, elements(10))
+	require.Len(t, pages, 1)
+	assert.Len(t, pages[0].Content, 10)
+	assert.Nil(t, pages[0].Pagination)
+}
+
+func TestPaginateLargeResourceIntoPages(t *testing.T) {
+	paginator := NewPaginator(4, time.Minute)
+
+	pages := paginator.Paginate("MAM/acme.com///1", nil, elements(10))
+	require.Len(t, pages, 3)
+	assert.Equal(t, []any{8, 9}, pages[2].Content)
+
+	id := pages[0].Pagination.PaginationId
+	assert.NotEmpty(t, id)
+	for i, page := range pages {
+		assert.Equal(t, api.PaginationResponse{TotalCount: 10, PerPage: 4, Page: uint32(i + 1), HasNext: i < 2, PaginationId: id}, *page.Pagination)
+	}
+	// pages published without request are not kept
+	assert.Equal(t, 0, paginator.Len())
+}
+
+func TestPaginateListResource(t *testing.T) {
+	paginator := NewPaginator(2, time.Minute)
+
+	pages := paginator.Paginate("MAM/acme.com///1", nil, func() ([]any, *api.Locale) {
+		return []any{[]string{"a", "b", "c"}}, nil
+	})
+	require.Len(t, pages, 2)
+	assert.Equal(t, []any{[]string{"a", "b"}}, pages[0].Content)
+	assert.Equal(t, []any{[]string{"c"}}, pages[1].Content)
+	assert.Equal(t, uint32(3), pages[0].Pagination.TotalCount)
+}
+
+func TestPaginateRequestedPagesFromSnapshot(t *testing.T) {
+	paginator := NewPaginator(0, time.Minute)
+	locale := &api.Locale{Locale: "de-DE"}
+	calls := 0
+	get := func() ([]any, *api.Locale) {
+		calls++
+		content, _ := elements(5 + calls)()
+		return content, locale
+	}
+
+	pages := paginator.Paginate("MAM/acme.com///1", &api.GetRequest{Pagination: &api.PaginationRequest{PerPage: 2, Page: 1}}, get)
+	require.Len(t, pages, 1)
+	first := pages[0].Pagination
+	assert.Equal(t, uint32(6), first.TotalCount)
+	assert.True(t, first.HasNext)
+	assert.Equal(t, locale, pages[0].Locale)
+	assert.Equal(t, 1, paginator.Len())
+
+	// the following pages are served from the snapshot of the first request
+	pages = paginator.Paginate("MAM/acme.com///1", &api.GetRequest{Pagination: &api.PaginationRequest{PerPage: 2, Page: 3, PaginationId: first.PaginationId}}, get)
+	require.Len(t, pages, 1)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []any{4, 5}, pages[0].Content)
+	assert.Equal(t, api.PaginationResponse{TotalCount: 6, PerPage: 2, Page: 3, HasNext: false, PaginationId: first.PaginationId}, *pages[0].Pagination)
+	assert.Equal(t, locale, pages[0].Locale)
+	// the snapshot is removed with the last page
+	assert.Equal(t, 0, paginator.Len())
+
+	// an unknown PaginationId starts a new snapshot
+	pages = paginator.Paginate("MAM/acme.com///1", &api.GetRequest{Pagination: &api.PaginationRequest{PerPage: 2, Page: 2, PaginationId: first.PaginationId}}, get)
+	assert.Equal(t, 2, calls)
+	assert.NotEqual(t, first.PaginationId, pages[0].Pagination.PaginationId)
+	assert.Equal(t, uint32(7), pages[0].Pagination.TotalCount)
+}
+
+func TestPaginateSnapshotExpires(t *testing.T) {
+	paginator := NewPaginator(0, time.Minute)
+	now := time.Now()
+	paginator.now = func() time.Time { return now }
+
+	pages := paginator.Paginate("MAM/acme.com///1", &api.GetRequest{Pagination: &api.PaginationRequest{PerPage: 2}}, elements(5))
+	assert.Equal(t, uint32(1), pages[0].Pagination.Page)
+	assert.Equal(t, 1, paginator.Len())
+
+	now = now.Add(2 * time.Minute)
+	pages = paginator.Paginate("MAM/acme.com///1", &api.GetRequest{Pagination: &api.PaginationRequest{PerPage: 2, Page: 2, PaginationId: pages[0].Pagination.PaginationId}}, elements(3))
+	assert.Equal(t, uint32(3), pages[0].Pagination.TotalCount)
+}
+
+func TestPaginateKeepsSnapshotsPerPublication(t *testing.T) {
+	paginator := NewPaginator(0, time.Minute)
+	request := &api.GetRequest{Pagination: &api.PaginationRequest{PerPage: 2, Page: 1}}
+
+	first := paginator.Paginate("Data/acme.com///1", request, elements(5))
+	second := paginator.Paginate("Data/acme.com///3", request, elements(3))
+	require.Len(t, first, 1)
+	require.Len(t, second, 1)
+	assert.Equal(t, 2, paginator.Len())
+
+	// a Get request is answered by both publications, only the owner of the PaginationId responds
+	next := &api.GetRequest{Pagination: &api.PaginationRequest{PerPage: 2, Page: 3, PaginationId: first[0].Pagination.PaginationId}}
+	assert.Empty(t, paginator.Paginate("Data/acme.com///3", next, elements(3)))
+	pages := paginator.Paginate("Data/acme.com///1", next, elements(3))
+	require.Len(t, pages, 1)
+	assert.Equal(t, []any{4}, pages[0].Content)
+	assert.Equal(t, uint32(5), pages[0].Pagination.TotalCount)
+
+	// the snapshot of the other publication is kept
+	assert.Equal(t, 1, paginator.Len())
+	next.Pagination.PaginationId = second[0].Pagination.PaginationId
+	next.Pagination.Page = 2
+	pages = paginator.Paginate("Data/acme.com///3", next, elements(1))
+	require.Len(t, pages, 1)
+	assert.Equal(t, []any{2}, pages[0].Content)
+	assert.Equal(t, 0, paginator.Len())
+}
@@ -2,6 +2,7 @@ package publication
 
 import (
 	"github.com/OI4/oi4-oec-service-go/service/api"
+	"time"
 )
 
 // Impl PublicationImpl we definitely need a mutex there :D
@@ -18,6 +19,8 @@ type Impl struct {
 	statusCode              *api.StatusCode
 	source                  *api.Oi4Identifier
 	dataSetWriterId         uint16
+	retained                bool
+	messageExpiry           time.Duration
 	//Data                    T
 	getDataFunc        func() any
 	stopIntervalTicker chan struct{}
@@ -50,7 +53,11 @@ func (p *Impl) GetSource() *api.Oi4Identifier {
 	return p.source
 }
 
+// GetDataSetWriterId returns the DataSetWriterId assigned by the application, it changes if the id is reassigned
 func (p *Impl) GetDataSetWriterId() uint16 {
+	if p.application != nil {
+		return p.application.GetDataSetWriterId(p.resource, p.source, p.filter)
+	}
 	return p.dataSetWriterId
 }
 
@@ -66,6 +73,10 @@ func (p *Impl) GetPublicationMode() *api.PublicationMode {
 	return p.publicationMode
 }
 
+func (p *Impl) IsRetained() bool {
+	return p.retained
+}
+
 func (p *Impl) publishOnRegistration() bool {
 	return p.doPublishOnRegistration
 }
@@ -99,7 +110,21 @@ func (p *Impl) TriggerPublication(trigger api.Trigger, correlationId *string) bo
 	return true
 }
 
+// TriggerRequestedPublication publishes the filter, page and locale requested by a Get request
+func (p *Impl) TriggerRequestedPublication(request api.GetRequest) bool {
+	if !p.ShouldPublicate(api.OnRequest) {
+		return false
+	}
+
+	p.publish(&request.MessageId, &request)
+	return true
+}
+
 func (p *Impl) triggerPublication(correlationId *string) {
+	p.publish(correlationId, nil)
+}
+
+func (p *Impl) publish(correlationId *string, request *api.GetRequest) {
 	if p.application == nil {
 		return
 	}
@@ -107,36 +132,84 @@ func (p *Impl) triggerPublication(correlationId *string) {
 	resource := p.GetResource()
 	source := p.GetOi4Source()
 
-	data := source.Get(resource, p.filter)
-
-	if data == nil || len(data) == 0 {
-		return
+	filter := p.filter
+	if request != nil && request.Filter != nil {
+		filter = request.Filter
 	}
 
-	content := make([]api.PublicationContent, len(data))
-	for i, single := range data {
-		if single == nil {
+	pages := p.paginate(paginationKey(resource, source, filter), request, func() ([]any, *api.Locale) {
+		return getData(source, resource, filter, request)
+	})
+
+	for _, page := range pages {
+		if len(page.Content) == 0 && page.Pagination == nil {
 			continue
 		}
-		content[i] = api.PublicationContent{
-			StatusCode: p.statusCode,
-			Data:       single,
+
+		content := make([]api.PublicationContent, len(page.Content))
+		for i, single := range page.Content {
+			if single == nil {
+				continue
+			}
+			content[i] = api.PublicationContent{
+				StatusCode: p.statusCode,
+				Data:       single,
+			}
+		}
+
+		message := api.PublicationMessage{
+			Filter:   filter,
+			Resource: resource,
+			//StatusCode: api.Status_Good,
+			//publicationMode: p.publicationMode,
+			CorrelationId: correlationId,
+			Source:        source.GetOi4Identifier(),
+			//Filter:        p.GetFilter(),
+			Content: content,
+			// a response, a requested filter, a page or a translation must not replace the retained resource
+			Retained:      p.retained && correlationId == nil && request == nil && page.Pagination == nil && page.Locale == nil,
+			MessageExpiry: p.messageExpiry,
+			Pagination:    page.Pagination,
+			Locale:        page.Locale,
+
+			DataSetWriterId: p.GetDataSetWriterId(),
 		}
+		//message.Data = source.Get(resource)
+
+		p.application.SendPublicationMessage(message)
 	}
+}
 
-	message := api.PublicationMessage{
-		Filter:   p.filter,
-		Resource: resource,
-		//StatusCode: api.Status_Good,
-		//publicationMode: p.publicationMode,
-		CorrelationId: correlationId,
-		Source:        source.GetOi4Identifier(),
-		//Filter:        p.GetFilter(),
-		Content: content,
+// paginate splits the resource into pages with the paginator of the application
+func (p *Impl) paginate(key string, request *api.GetRequest, get func() ([]any, *api.Locale)) []api.Page {
+	if paginator := p.application.GetPaginator(); paginator != nil {
+		return paginator.Paginate(key, request, get)
 	}
-	//message.Data = source.Get(resource)
 
-	p.application.SendPublicationMessage(message)
+	content, locale := get()
+	return []api.Page{{Content: content, Locale: locale}}
+}
+
+// paginationKey identifies the snapshots of a publication, a Get request is answered by the publications of every
+// source and filter
+func paginationKey(resource api.ResourceType, source api.BaseSource, filter *api.Filter) string {
+	key := string(resource) + "/" + source.GetOi4Identifier().ToString()
+	if filter != nil {
+		key += "/" + filter.String()
+	}
+	return key
+}
+
+// getData returns the resource in the requested locale if the source provides it, the returned locale is nil otherwise
+func getData(source api.BaseSource, resource api.ResourceType, filter *api.Filter, request *api.GetRequest) ([]any, *api.Locale) {
+	if request != nil && request.Locale != nil {
+		if localizedSource, ok := source.(api.LocalizedSource); ok {
+			if data, ok := localizedSource.GetLocalized(resource, filter, request.Locale.Locale); ok {
+				return data, request.Locale
+			}
+		}
+	}
+	return source.Get(resource, filter), nil
 }
 
 func getPublicationMode(mode *api.PublicationMode) api.PublicationMode {
@@ -27,6 +27,7 @@ func NewResourcePublicationWithFilter(application api.Oi4Application, oi4Source
 func NewMAMPublication(application api.Oi4Application, oi4Source api.BaseSource) *Impl {
 	mam := NewResourcePublication(application, oi4Source, api.ResourceMam)
 	mam.doPublishOnRegistration = true
+	mam.retained = true
 	return mam
 }
 
@@ -35,6 +36,7 @@ func NewHealthPublication(application api.Oi4Application, oi4Source api.BaseSour
 								Oi4Source(oi4Source).                                      //
 								Resource(api.ResourceHealth).                              //
 								PublicationMode(api.PublicationMode_APPLICATION_SOURCE_5). //
+								Retain(true).                                              //
 								Build()
 }
 
@@ -46,6 +48,9 @@ type BaseBuilder[T any] interface {
 	StatusCode(status *api.StatusCode) T
 	Filter(filter *api.Filter) T
 	DataFunc(getDataFunc func() any) T
+	Retain(retain bool) T
+	// MessageExpiry lets a MQTT 5 broker discard the publication if it cannot be delivered within the expiry
+	MessageExpiry(expiry time.Duration) T
 }
 
 type Builder interface {
@@ -66,6 +71,8 @@ type BuilderImpl struct {
 	publicationConfig api.PublicationConfig
 	statusCode        *api.StatusCode
 	getDataFunc       func() any
+	retained          bool
+	messageExpiry     time.Duration
 }
 
 func NewBuilder(application api.Oi4Application) *BuilderImpl {
@@ -128,6 +135,18 @@ func (p *BuilderImpl) DataFunc(getDataFunc func() any) Builder {
 	return p
 }
 
+func (p *BuilderImpl) Retain(retain bool) Builder {
+	p.retained = retain
+
+	return p
+}
+
+func (p *BuilderImpl) MessageExpiry(expiry time.Duration) Builder {
+	p.messageExpiry = expiry
+
+	return p
+}
+
 func (p *BuilderImpl) Build() *Impl {
 	oi4Identifier := p.oi4Source.GetOi4Identifier()
 	pub := Impl{
@@ -138,12 +157,16 @@ func (p *BuilderImpl) Build() *Impl {
 
 		oi4Source:               p.oi4Source,
 		doPublishOnRegistration: p.doPublishOnRegistration,
-		dataSetWriterId:         opc.GetDataSetWriterId(p.resource, oi4Identifier),
 
 		publicationMode:   p.publicationMode,
 		publicationConfig: p.publicationConfig,
 		statusCode:        p.statusCode,
 		getDataFunc:       p.getDataFunc,
+		retained:          p.retained,
+		messageExpiry:     p.messageExpiry,
+	}
+	if p.application == nil {
+		pub.dataSetWriterId = opc.GetDataSetWriterId(p.resource, oi4Identifier)
 	}
 	pub.id = fmt.Sprintf("%p", &pub)
 	return &pub
@@ -216,6 +239,18 @@ func (p *IntervalBuilderImpl) DataFunc(getDataFunc func() any) IntervalBuilder {
 	return p
 }
 
+func (p *IntervalBuilderImpl) Retain(retain bool) IntervalBuilder {
+	p.retained = retain
+
+	return p
+}
+
+func (p *IntervalBuilderImpl) MessageExpiry(expiry time.Duration) IntervalBuilder {
+	p.messageExpiry = expiry
+
+	return p
+}
+
 func (p *IntervalBuilderImpl) PublicationInterval(publicationInterval time.Duration) IntervalBuilder {
 	p.publicationInterval = publicationInterval
 
@@ -21,6 +21,7 @@ func NewApplicationSourceImpl(mam api.MasterAssetModel, options ...Option) *Appl
 	}
 
 	source.publicationProvider = &source
+	source.subscriptionProvider = &source
 
 	return &source
 }
@@ -46,3 +47,10 @@ func (source *ApplicationSourceImpl) RemoveSource(sourceToRemove api.Oi4Identifi
 func (source *ApplicationSourceImpl) GetPublications() []api.Publication {
 	return source.application.GetPublications()
 }
+
+func (source *ApplicationSourceImpl) GetSubscriptions() []api.Subscription {
+	if source.application == nil {
+		return nil
+	}
+	return source.application.GetSubscriptions()
+}
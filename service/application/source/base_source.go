@@ -21,10 +21,13 @@ type BaseSourceImpl struct {
 	application api.Oi4Application
 
 	publicationProvider api.PublicationProvider
+	// subscriptionProvider adds the subscriptions of the application to the subscription list, nil for assets
+	subscriptionProvider api.SubscriptionProvider
 
 	dataFn        func(source api.BaseSource, filter *api.Filter) []api.Data
 	dataWrapperFn func([]api.Data) []any
 	healthFn      func(source api.BaseSource) api.Health
+	localizerFn   func(resource api.ResourceType, data any, locale string) (any, bool)
 }
 
 func newBaseImpl(mam api.MasterAssetModel, options ...Option) *BaseSourceImpl {
@@ -64,6 +67,13 @@ func (source *BaseSourceImpl) GetMasterAssetModel() api.MasterAssetModel {
 	return source.mam
 }
 
+func (source *BaseSourceImpl) UpdateMasterAssetModel(mam api.MasterAssetModel) {
+	source.mam = mam
+	if source.application != nil {
+		source.application.ResourceChanged(api.ResourceMam, source, nil)
+	}
+}
+
 func (source *BaseSourceImpl) GetHealth() api.Health {
 	if source.healthFn != nil {
 		return source.healthFn(source)
@@ -102,6 +112,10 @@ func (source *BaseSourceImpl) GetConfig() api.PublishConfig {
 	return source.config
 }
 
+func (source *BaseSourceImpl) ApplyConfig(config api.PublishConfig) {
+	source.config = config
+}
+
 func (source *BaseSourceImpl) GetLicense() api.License {
 	return source.license
 }
@@ -154,7 +168,18 @@ func (source *BaseSourceImpl) GetPublicationList() []api.PublicationList {
 }
 
 func (source *BaseSourceImpl) GetSubscriptionList() []api.SubscriptionList {
-	return source.subscriptionList
+	if source.subscriptionProvider == nil {
+		return source.subscriptionList
+	}
+
+	subscriptionList := slices.Clone(source.subscriptionList)
+	for _, subscription := range source.subscriptionProvider.GetSubscriptions() {
+		subscriptionList = append(subscriptionList, api.SubscriptionList{
+			TopicPath: subscription.GetTopic(),
+			Config:    api.SubsciptionConfig_NONE_0,
+		})
+	}
+	return subscriptionList
 }
 
 func (source *BaseSourceImpl) GetReferenceDesignation() api.ReferenceDesignation {
@@ -194,6 +219,25 @@ func (source *BaseSourceImpl) Get(resourceType api.ResourceType, filter *api.Fil
 	return toAnySlice(getResource())
 }
 
+// GetLocalized translates the resource with the localizer, it returns false if no localizer is configured or the
+// localizer does not support the locale
+func (source *BaseSourceImpl) GetLocalized(resourceType api.ResourceType, filter *api.Filter, locale string) ([]any, bool) {
+	if source.localizerFn == nil {
+		return nil, false
+	}
+
+	data := source.Get(resourceType, filter)
+	result := make([]any, 0, len(data))
+	for _, single := range data {
+		localized, ok := source.localizerFn(resourceType, single, locale)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, localized)
+	}
+	return result, true
+}
+
 func (source *BaseSourceImpl) wrapData(data []api.Data) []any {
 	if data == nil || len(data) == 0 {
 		return nil
@@ -245,6 +289,13 @@ func WithDataWrapperFn(fn func([]api.Data) []any) Option {
 	}
 }
 
+// WithLocalizer translates the resources requested with a Locale, the localizer returns false for an unsupported locale
+func WithLocalizer(fn func(resource api.ResourceType, data any, locale string) (any, bool)) Option {
+	return func(s *BaseSourceImpl) {
+		s.localizerFn = fn
+	}
+}
+
 func WithProfile(profile api.Profile) Option {
 	return func(s *BaseSourceImpl) {
 		s.profile = profile
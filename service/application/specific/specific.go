@@ -0,0 +1,214 @@
+// Package specific provides the specific services of OT connectors, which expose the variables of a device through
+// api.VariableAccess. Every service is called with Call and answered with Reply, a variable is identified by the Name
+// of its FieldMetaData.
+//
+//   - Read(Name) → (Value)
+//   - Write(Name, Value) converts the value to the type of the FieldMetaData before it is written.
+//   - Subscribe(Name, Interval) → (DataSetWriterId) publishes the variable as Data with the name as filter every
+//     Interval milliseconds, until Unsubscribe(Name) is called.
+//   - GenericMethod: Call(MethodId, InputArguments) → (OutputArguments) calls a device specific method.
+package specific
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	pub "github.com/OI4/oi4-oec-service-go/service/application/publication"
+	"sync"
+	"time"
+)
+
+const (
+	MethodRead        = "Read"
+	MethodWrite       = "Write"
+	MethodSubscribe   = "Subscribe"
+	MethodUnsubscribe = "Unsubscribe"
+	MethodCall        = "Call"
+
+	// DefaultInterval of a subscription without interval
+	DefaultInterval = time.Second
+	// MinInterval limits the interval of a subscription
+	MinInterval = 100 * time.Millisecond
+)
+
+// Host registers the methods and the publications of the subscriptions, it is implemented by the assets
+type Host interface {
+	api.MethodRegistry
+	RegisterPublication(publication api.Publication) error
+	RemovePublication(publication api.Publication)
+}
+
+type Service struct {
+	application api.Oi4Application
+	source      api.BaseSource
+	access      api.VariableAccess
+	host        Host
+
+	// subscriptions are the publications of the subscribed variables by name
+	subscriptions map[string]api.Publication
+	mutex         sync.Mutex
+}
+
+// New creates the services of the variables of the source
+func New(application api.Oi4Application, source api.BaseSource, access api.VariableAccess) *Service {
+	return &Service{
+		application:   application,
+		source:        source,
+		access:        access,
+		subscriptions: make(map[string]api.Publication),
+	}
+}
+
+// Register registers the methods of all services with the host, which also hosts the publications of the subscriptions
+func (s *Service) Register(host Host) error {
+	s.host = host
+
+	methods := []struct {
+		service  api.ResourceType
+		methodId string
+		method   api.Method
+	}{
+		{api.ResourceRead, MethodRead, api.Method{
+			InputArguments: []api.MethodArgument{api.NewArgument[string]("Name")},
+			Handler:        s.read,
+		}},
+		{api.ResourceWrite, MethodWrite, api.Method{
+			InputArguments: []api.MethodArgument{api.NewArgument[string]("Name"), api.NewArgument[any]("Value")},
+			Handler:        s.write,
+		}},
+		{api.ResourceSubscribe, MethodSubscribe, api.Method{
+			InputArguments: []api.MethodArgument{api.NewArgument[string]("Name"), api.NewOptionalArgument[uint32]("Interval")},
+			Handler:        s.subscribe,
+		}},
+		{api.ResourceUnsubscribe, MethodUnsubscribe, api.Method{
+			InputArguments: []api.MethodArgument{api.NewArgument[string]("Name")},
+			Handler:        s.unsubscribe,
+		}},
+		{api.ResourceGenericMethod, MethodCall, api.Method{
+			InputArguments: []api.MethodArgument{api.NewArgument[string]("MethodId"), api.NewOptionalArgument[[]any]("InputArguments")},
+			Handler:        s.call,
+		}},
+	}
+
+	for _, current := range methods {
+		if err := host.RegisterMethod(current.service, current.methodId, current.method); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close removes the publications of all subscriptions
+func (s *Service) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for name, publication := range s.subscriptions {
+		s.host.RemovePublication(publication)
+		delete(s.subscriptions, name)
+	}
+}
+
+func (s *Service) read(_ api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	name := inputArguments[0].(string)
+	if _, ok := s.variable(name); !ok {
+		return nil, api.Status_BadNodeIdUnknown
+	}
+
+	value, status := s.access.ReadVariable(name)
+	if status != api.Status_Good {
+		return nil, status
+	}
+	return []any{value}, api.Status_Good
+}
+
+func (s *Service) write(_ api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	name := inputArguments[0].(string)
+	field, ok := s.variable(name)
+	if !ok {
+		return nil, api.Status_BadNodeIdUnknown
+	}
+
+	value, err := field.ConvertValue(inputArguments[1])
+	if err != nil {
+		return nil, api.Status_BadTypeMismatch
+	}
+	return nil, s.access.WriteVariable(name, value)
+}
+
+// subscribe creates the publication of the variable, a subscribed variable gets the new interval
+func (s *Service) subscribe(_ api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	name := inputArguments[0].(string)
+	if _, ok := s.variable(name); !ok {
+		return nil, api.Status_BadNodeIdUnknown
+	}
+	interval := DefaultInterval
+	if milliseconds, ok := inputArguments[1].(uint32); ok && milliseconds > 0 {
+		interval = max(time.Duration(milliseconds)*time.Millisecond, MinInterval)
+	}
+
+	publication := pub.NewIntervalBuilder(s.application, interval). //
+									Oi4Source(&variableSource{BaseSource: s.source, access: s.access}). //
+									Resource(api.ResourceData).                                         //
+									Filter(api.NewFilter(name)).                                        //
+									PublicationMode(api.PublicationMode_APPLICATION_SOURCE_FILTER_8).   //
+									Build()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if previous, ok := s.subscriptions[name]; ok {
+		s.host.RemovePublication(previous)
+	}
+	if err := s.host.RegisterPublication(publication); err != nil {
+		delete(s.subscriptions, name)
+		return nil, api.Status_BadUnexpectedError
+	}
+	s.subscriptions[name] = publication
+	return []any{publication.GetDataSetWriterId()}, api.Status_Good
+}
+
+func (s *Service) unsubscribe(_ api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	name := inputArguments[0].(string)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	publication, ok := s.subscriptions[name]
+	if !ok {
+		return nil, api.Status_BadNoSubscription
+	}
+	s.host.RemovePublication(publication)
+	delete(s.subscriptions, name)
+	return nil, api.Status_Good
+}
+
+func (s *Service) call(_ api.BaseSource, inputArguments []any) ([]any, api.StatusCode) {
+	methodArguments, _ := inputArguments[1].([]any)
+	return s.access.CallGenericMethod(inputArguments[0].(string), methodArguments)
+}
+
+func (s *Service) variable(name string) (api.FieldMetaData, bool) {
+	for _, field := range s.access.GetVariables() {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return api.FieldMetaData{}, false
+}
+
+// variableSource publishes the value of the variable filtered by a subscription as Data
+type variableSource struct {
+	api.BaseSource
+	access api.VariableAccess
+}
+
+func (source *variableSource) Get(resource api.ResourceType, filter *api.Filter) []any {
+	if resource != api.ResourceData || filter == nil {
+		return source.BaseSource.Get(resource, filter)
+	}
+
+	value, status := source.access.ReadVariable(filter.String())
+	if status != api.Status_Good {
+		return nil
+	}
+	return []any{api.NewOi4Data(value).GetData()}
+}
@@ -0,0 +1,132 @@
+package specific
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/internal/methodtest"
+	"github.com/OI4/oi4-oec-service-go/service/application/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type hostMock struct {
+	methodtest.Registry
+	publications []api.Publication
+}
+
+func (h *hostMock) RegisterPublication(publication api.Publication) error {
+	h.publications = append(h.publications, publication)
+	return nil
+}
+
+func (h *hostMock) RemovePublication(publication api.Publication) {
+	for i, current := range h.publications {
+		if current == publication {
+			h.publications = append(h.publications[:i], h.publications[i+1:]...)
+			return
+		}
+	}
+}
+
+type deviceMock struct {
+	values map[string]any
+}
+
+func (d *deviceMock) GetVariables() []api.FieldMetaData {
+	return []api.FieldMetaData{
+		{Name: "Speed", BuiltInType: api.BuiltInType_UInt16, ValueRank: api.ValueRankScalar},
+		{Name: "Locked", BuiltInType: api.BuiltInType_Boolean, ValueRank: api.ValueRankScalar},
+	}
+}
+
+func (d *deviceMock) ReadVariable(name string) (any, api.StatusCode) {
+	if name == "Locked" {
+		return nil, api.Status_BadNotReadable
+	}
+	return d.values[name], api.Status_Good
+}
+
+func (d *deviceMock) WriteVariable(name string, value any) api.StatusCode {
+	d.values[name] = value
+	return api.Status_Good
+}
+
+func (d *deviceMock) CallGenericMethod(methodId string, inputArguments []any) ([]any, api.StatusCode) {
+	if methodId != "Home" {
+		return nil, api.Status_BadMethodInvalid
+	}
+	return []any{len(inputArguments)}, api.Status_Good
+}
+
+func newTestService(t *testing.T) (*hostMock, *deviceMock, api.BaseSource) {
+	device := &deviceMock{values: map[string]any{"Speed": uint16(1200)}}
+	assetSource := source.NewAssetSourceImpl(api.MasterAssetModel{ManufacturerUri: "acme.com", SerialNumber: "3"})
+	host := &hostMock{Registry: make(methodtest.Registry)}
+	require.NoError(t, New(nil, assetSource, device).Register(host))
+	return host, device, assetSource
+}
+
+func TestReadAndWriteVariables(t *testing.T) {
+	host, device, assetSource := newTestService(t)
+	read := host.Get(api.ResourceRead, MethodRead).Handler
+	write := host.Get(api.ResourceWrite, MethodWrite).Handler
+
+	value, status := read(assetSource, []any{"Speed"})
+	assert.Equal(t, api.Status_Good, status)
+	assert.Equal(t, []any{uint16(1200)}, value)
+	_, status = read(assetSource, []any{"Unknown"})
+	assert.Equal(t, api.Status_BadNodeIdUnknown, status)
+	_, status = read(assetSource, []any{"Locked"})
+	assert.Equal(t, api.Status_BadNotReadable, status)
+
+	_, status = write(assetSource, []any{"Speed", 1500.0})
+	assert.Equal(t, api.Status_Good, status)
+	assert.Equal(t, uint16(1500), device.values["Speed"])
+
+	_, status = write(assetSource, []any{"Speed", -1.0})
+	assert.Equal(t, api.Status_BadTypeMismatch, status)
+	_, status = write(assetSource, []any{"Locked", "yes"})
+	assert.Equal(t, api.Status_BadTypeMismatch, status)
+	assert.Equal(t, uint16(1500), device.values["Speed"])
+}
+
+func TestSubscribeCreatesPublications(t *testing.T) {
+	host, _, assetSource := newTestService(t)
+	subscribe := host.Get(api.ResourceSubscribe, MethodSubscribe).Handler
+	unsubscribe := host.Get(api.ResourceUnsubscribe, MethodUnsubscribe).Handler
+
+	_, status := subscribe(assetSource, []any{"Unknown", nil})
+	assert.Equal(t, api.Status_BadNodeIdUnknown, status)
+
+	_, status = subscribe(assetSource, []any{"Speed", uint32(500)})
+	require.Equal(t, api.Status_Good, status)
+	require.Len(t, host.publications, 1)
+	publication := host.publications[0]
+	assert.Equal(t, api.ResourceData, publication.GetResource())
+	assert.Equal(t, "Speed", publication.GetFilter().String())
+	assert.Equal(t, []any{map[string]any{"Pv": uint16(1200)}}, publication.GetOi4Source().Get(api.ResourceData, publication.GetFilter()))
+
+	// a new interval replaces the publication
+	_, status = subscribe(assetSource, []any{"Speed", uint32(1000)})
+	require.Equal(t, api.Status_Good, status)
+	require.Len(t, host.publications, 1)
+	assert.NotSame(t, publication, host.publications[0])
+
+	_, status = unsubscribe(assetSource, []any{"Speed"})
+	assert.Equal(t, api.Status_Good, status)
+	assert.Empty(t, host.publications)
+	_, status = unsubscribe(assetSource, []any{"Speed"})
+	assert.Equal(t, api.Status_BadNoSubscription, status)
+}
+
+func TestGenericMethod(t *testing.T) {
+	host, _, assetSource := newTestService(t)
+	call := host.Get(api.ResourceGenericMethod, MethodCall).Handler
+
+	outputArguments, status := call(assetSource, []any{"Home", []any{1.0, "fast"}})
+	assert.Equal(t, api.Status_Good, status)
+	assert.Equal(t, []any{2}, outputArguments)
+
+	_, status = call(assetSource, []any{"Unknown", nil})
+	assert.Equal(t, api.Status_BadMethodInvalid, status)
+}
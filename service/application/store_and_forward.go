@@ -0,0 +1,125 @@
+package application
+
+import (
+	"errors"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/application/outbox"
+	"github.com/OI4/oi4-oec-service-go/service/container"
+	"path/filepath"
+	"time"
+)
+
+const outboxDirectory = "outbox"
+
+// errReplayInterrupted stops the replay, as the client would buffer the message offline instead of publishing it
+var errReplayInterrupted = errors.New("the connection was lost during the replay")
+
+// openOutbox opens the outbox below the data path, if the outbox is enabled and the container provides a data path
+func (app *Oi4ApplicationImpl) openOutbox(storage container.Storage) error {
+	if app.outboxConfig == nil || storage.ApplicationSpecificStorages == nil || storage.ApplicationSpecificStorages.DataPath == "" {
+		return nil
+	}
+
+	var err error
+	app.outbox, err = outbox.Open(filepath.Join(storage.ApplicationSpecificStorages.DataPath, outboxDirectory), *app.outboxConfig)
+	return err
+}
+
+// storeInOutbox stores a QoS 1 or 2 message while the client is disconnected or older messages are not yet replayed,
+// so the publication order is kept. It returns false if the message has to be published directly.
+func (app *Oi4ApplicationImpl) storeInOutbox(topic string, retained bool, messageExpiry time.Duration, networkMessage *api.NetworkMessage) bool {
+	if app.outbox == nil || app.qos == 0 {
+		return false
+	}
+	if app.mqttClient.IsConnected() && app.outbox.Len() == 0 {
+		return false
+	}
+
+	dropped, err := app.outbox.Add(outbox.Entry{Topic: topic, Qos: app.qos, Retained: retained, MessageExpiry: messageExpiry, Message: networkMessage})
+	if err != nil {
+		app.logger.Warnf("Failed to store message of topic %s in the outbox: %v", topic, err)
+	}
+	if dropped > 0 {
+		app.logger.Warnf("Outbox overflow, dropped %d message(s)", dropped)
+		app.reportOutboxOverflow()
+	}
+
+	if app.mqttClient.IsConnected() {
+		app.replayOutbox()
+	}
+	return true
+}
+
+// replayOutbox publishes the stored messages in order, only one replay runs at a time. A message is only removed from
+// the outbox if the client was connected before and after its publication, otherwise it is replayed again.
+func (app *Oi4ApplicationImpl) replayOutbox() {
+	if app.outbox == nil {
+		return
+	}
+
+	// a message stored after the replay found the outbox empty, but before the flag was cleared, lost the
+	// CompareAndSwap to this replay, so the outbox is checked again
+	for app.outboxReplaying.CompareAndSwap(false, true) {
+		err := app.outbox.Replay(app.replayEntry)
+		app.outboxReplaying.Store(false)
+
+		if err != nil {
+			app.logger.Warnf("Failed to replay the outbox, %d message(s) left: %v", app.outbox.Len(), err)
+			return
+		}
+		if app.outbox.Len() == 0 {
+			app.restoreOutboxHealth()
+			return
+		}
+	}
+}
+
+// replayEntry publishes a stored message with the remaining message expiry, an expired message is dropped
+func (app *Oi4ApplicationImpl) replayEntry(entry outbox.Entry) error {
+	if !app.mqttClient.IsConnected() {
+		return errReplayInterrupted
+	}
+
+	publishOptions := make([]api.PublishOption, 0)
+	if entry.MessageExpiry > 0 {
+		remaining := entry.MessageExpiry - time.Since(entry.Stored)
+		if remaining <= 0 {
+			return nil
+		}
+		publishOptions = append(publishOptions, api.WithMessageExpiry(remaining))
+	}
+
+	if err := app.mqttClient.PublishResource(entry.Topic, entry.Qos, entry.Retained, entry.Message, publishOptions...); err != nil {
+		return err
+	}
+	if !app.mqttClient.IsConnected() {
+		return errReplayInterrupted
+	}
+	return nil
+}
+
+// reportOutboxOverflow sets the application health to OFF_SPEC, as messages got lost
+func (app *Oi4ApplicationImpl) reportOutboxOverflow() {
+	app.outboxHealthMutex.Lock()
+	if app.healthBeforeOverflow != nil {
+		app.outboxHealthMutex.Unlock()
+		return
+	}
+	health := app.applicationSource.GetHealth()
+	app.healthBeforeOverflow = &health
+	app.outboxHealthMutex.Unlock()
+
+	app.applicationSource.UpdateHealth(api.Health{Health: api.Health_OffSpec, HealthScore: health.HealthScore})
+}
+
+// restoreOutboxHealth restores the health from before the overflow once the outbox is drained
+func (app *Oi4ApplicationImpl) restoreOutboxHealth() {
+	app.outboxHealthMutex.Lock()
+	health := app.healthBeforeOverflow
+	app.healthBeforeOverflow = nil
+	app.outboxHealthMutex.Unlock()
+
+	if health != nil {
+		app.applicationSource.UpdateHealth(*health)
+	}
+}
@@ -1,8 +1,8 @@
 package subscription
 
 import (
-	"encoding/json"
 	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/codec"
 	tp "github.com/OI4/oi4-oec-service-go/service/topic"
 	"github.com/eclipse/paho.mqtt.golang"
 )
@@ -13,6 +13,52 @@ type MessageHandlerImpl struct {
 }
 
 func NewMessageHandler(app api.Oi4Application, handler func(resource api.ResourceType, source *api.Oi4Identifier, networkMessage api.NetworkMessage, topic *tp.Topic), opts ...func(*MessageHandlerImpl)) *MessageHandlerImpl {
+	return NewMessageHandlerWithProperties(app, func(resource api.ResourceType, source *api.Oi4Identifier, networkMessage api.NetworkMessage, topic *tp.Topic, _ *api.MessageProperties) {
+		handler(resource, source, networkMessage, topic)
+	}, opts...)
+}
+
+// NewMessageHandlerWithProperties provides the MQTT 5 properties of the message to the handler, they are nil if the
+// client does not support MQTT 5. The CorrelationId of the NetworkMessage is taken from the correlation data, if the
+// message does not contain one.
+func NewMessageHandlerWithProperties(app api.Oi4Application, handler func(resource api.ResourceType, source *api.Oi4Identifier, networkMessage api.NetworkMessage, topic *tp.Topic, properties *api.MessageProperties), opts ...func(*MessageHandlerImpl)) *MessageHandlerImpl {
+	return newMessageHandler(app, func(message mqtt.Message, properties *api.MessageProperties) (func(topic *tp.Topic), error) {
+		networkMessage := api.NetworkMessage{}
+		if err := payloadCodec(message.Payload(), properties).Unmarshal(message.Payload(), &networkMessage); err != nil {
+			return nil, err
+		}
+		if networkMessage.CorrelationId == nil && properties != nil && len(properties.CorrelationData) > 0 {
+			correlationId := string(properties.CorrelationData)
+			networkMessage.CorrelationId = &correlationId
+		}
+
+		return func(topic *tp.Topic) {
+			handler(topic.Resource, topic.Source, networkMessage, topic, properties)
+		}, nil
+	}, opts...)
+}
+
+// NewServiceMessageHandler handles the ServiceNetworkMessages of Call and Reply topics, the service is the resource
+// level of the topic
+func NewServiceMessageHandler(app api.Oi4Application, handler func(service api.ResourceType, source *api.Oi4Identifier, serviceMessage api.ServiceNetworkMessage, topic *tp.Topic), opts ...func(*MessageHandlerImpl)) *MessageHandlerImpl {
+	return newMessageHandler(app, func(message mqtt.Message, properties *api.MessageProperties) (func(topic *tp.Topic), error) {
+		serviceMessage := api.ServiceNetworkMessage{}
+		if err := payloadCodec(message.Payload(), properties).Unmarshal(message.Payload(), &serviceMessage); err != nil {
+			return nil, err
+		}
+		if serviceMessage.CorrelationId == "" && properties != nil {
+			serviceMessage.CorrelationId = string(properties.CorrelationData)
+		}
+
+		return func(topic *tp.Topic) {
+			handler(topic.Resource, topic.Source, serviceMessage, topic)
+		}, nil
+	}, opts...)
+}
+
+// newMessageHandler decodes the payload of a message and passes it to the handler, once the topic is parsed and own
+// messages are skipped
+func newMessageHandler(app api.Oi4Application, decode func(message mqtt.Message, properties *api.MessageProperties) (func(topic *tp.Topic), error), opts ...func(*MessageHandlerImpl)) *MessageHandlerImpl {
 
 	messageHandler := &MessageHandlerImpl{
 		skipOwnMessage: true,
@@ -23,12 +69,17 @@ func NewMessageHandler(app api.Oi4Application, handler func(resource api.Resourc
 	}
 
 	handle := func(_ mqtt.Client, message mqtt.Message) {
-		networkMessage := api.NetworkMessage{}
-		err := json.Unmarshal(message.Payload(), &networkMessage)
+		var properties *api.MessageProperties
+		if propertiesMessage, ok := message.(api.PropertiesMessage); ok {
+			properties = propertiesMessage.Properties()
+		}
+
+		handler, err := decode(message, properties)
 		if err != nil {
 			app.GetLogger().Infof("%s %s topic:%s", "error unmarshalling network message", err, message.Topic())
 			return
 		}
+
 		topic, err := tp.ParseTopic(message.Topic())
 
 		if err != nil {
@@ -41,7 +92,7 @@ func NewMessageHandler(app api.Oi4Application, handler func(resource api.Resourc
 			return
 		}
 
-		handler(topic.Resource, topic.Source, networkMessage, topic)
+		handler(topic)
 	}
 
 	messageHandler.handler = handle
@@ -49,6 +100,16 @@ func NewMessageHandler(app api.Oi4Application, handler func(resource api.Resourc
 	return messageHandler
 }
 
+// payloadCodec returns the codec of the content type property or else the codec detected from the payload
+func payloadCodec(payload []byte, properties *api.MessageProperties) api.Codec {
+	if properties != nil {
+		if payloadCodec, ok := codec.ForContentType(properties.ContentType); ok {
+			return payloadCodec
+		}
+	}
+	return codec.Detect(payload)
+}
+
 func (m *MessageHandlerImpl) GetHandler() mqtt.MessageHandler {
 	return m.handler
 }
@@ -10,7 +10,7 @@ import (
 	"testing"
 )
 
-var validTopic = "Oi4/OTConnector/acme.com/FBC/fbc%183z/FBC#123/Get/MAM/acme.com/matches/m/42-A/F234#862"
+var validTopic = "Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/MAM/acme.com/matches/m,2F42-A/F234,23862"
 
 func TestExecuteValidMessage(t *testing.T) {
 	logger := zaptest.NewLogger(t)
@@ -96,10 +96,30 @@ func (a *applicationMockImpl) GetIntervalPublicationScheduler() api.IntervalPubl
 	panic("implement me")
 }
 
+func (a *applicationMockImpl) GetPaginator() api.Paginator {
+	panic("implement me")
+}
+
 func (a *applicationMockImpl) GetPublications() []api.Publication {
 	panic("implement me")
 }
 
+func (a *applicationMockImpl) GetSubscriptions() []api.Subscription {
+	panic("implement me")
+}
+
+func (a *applicationMockImpl) AddConnectionListener(_ api.ConnectionListener) {
+	panic("implement me")
+}
+
+func (a *applicationMockImpl) IsConnected() bool {
+	panic("implement me")
+}
+
+func (a *applicationMockImpl) GetDataSetWriterId(api.ResourceType, *api.Oi4Identifier, *api.Filter) uint16 {
+	panic("implement me")
+}
+
 func (a *applicationMockImpl) GetLogger() *zap.SugaredLogger {
 	return a.logger
 }
@@ -130,10 +150,18 @@ func (a *applicationSourceMock) UpdateData(data api.Data, dataTag string) {
 	panic("implement me")
 }
 
+func (a *applicationSourceMock) UpdateMasterAssetModel(_ api.MasterAssetModel) {
+	panic("implement me")
+}
+
 func (a *applicationSourceMock) GetConfig() api.PublishConfig {
 	panic("implement me")
 }
 
+func (a *applicationSourceMock) ApplyConfig(_ api.PublishConfig) {
+	panic("implement me")
+}
+
 func (a *applicationSourceMock) GetProfile() api.Profile {
 	panic("implement me")
 }
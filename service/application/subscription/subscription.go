@@ -2,6 +2,7 @@ package subscription
 
 import (
 	"github.com/OI4/oi4-oec-service-go/service/api"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
 	"github.com/google/uuid"
 )
 
@@ -12,13 +13,19 @@ type Impl struct {
 	interval uint32
 	config   api.SubscriptionConfig
 	qos      byte
+	group    string
 	handler  api.MessageHandler
 }
 
-func NewTopicSubscription(topic string, handler api.MessageHandler, opts ...func(*Impl)) *Impl {
+// TopicFilter is either a plain MQTT topic filter or a filter built with topic.NewFilter
+type TopicFilter interface {
+	string | *tp.Filter
+}
+
+func NewTopicSubscription[T TopicFilter](topic T, handler api.MessageHandler, opts ...func(*Impl)) *Impl {
 	subscription := &Impl{
 		id:       uuid.New().String(),
-		topic:    topic,
+		topic:    filterString(topic),
 		interval: 0,
 		config:   api.SubsciptionConfig_CONF_1,
 		qos:      1,
@@ -32,7 +39,20 @@ func NewTopicSubscription(topic string, handler api.MessageHandler, opts ...func
 	return subscription
 }
 
+func filterString[T TopicFilter](topic T) string {
+	switch filter := any(topic).(type) {
+	case *tp.Filter:
+		return filter.String()
+	default:
+		return filter.(string)
+	}
+}
+
+// GetTopic returns the topic filter, prefixed with $share/<group>/ for a shared subscription
 func (s *Impl) GetTopic() string {
+	if s.group != "" {
+		return tp.Shared(s.group, s.topic)
+	}
 	return s.topic
 }
 
@@ -41,7 +61,7 @@ func (s *Impl) GetQoS() byte {
 }
 
 func (s *Impl) GetID() string {
-	return s.topic
+	return s.GetTopic()
 }
 
 func (s *Impl) GetHandler() api.MessageHandler {
@@ -65,3 +85,11 @@ func WithQoS(qos byte) func(*Impl) {
 		s.qos = qos
 	}
 }
+
+// WithSharedGroup subscribes as member of a shared subscription group, every message is delivered to only one member
+// of the group. Replicas of a consumer use the same group to distribute the messages among each other.
+func WithSharedGroup(group string) func(*Impl) {
+	return func(s *Impl) {
+		s.group = group
+	}
+}
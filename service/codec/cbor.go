@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"bytes"
+	"github.com/fxamacker/cbor/v2"
+	"reflect"
+)
+
+var (
+	cborEncMode cbor.EncMode
+	cborDecMode cbor.DecMode
+)
+
+func init() {
+	var err error
+	// the json struct tags of the api types are used as field names, timestamps are encoded like in JSON
+	cborEncMode, err = cbor.EncOptions{
+		Time: cbor.TimeRFC3339Nano,
+	}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+
+	// untyped maps are decoded like with encoding/json, so payloads can be handled independent of the codec
+	cborDecMode, err = cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]any(nil)),
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// cborCodec prefixes the encoding with the self describe tag, which is the marker of the payload
+type cborCodec struct{}
+
+func (cborCodec) ContentType() string {
+	return ContentTypeCbor
+}
+
+func (cborCodec) Marshal(v any) ([]byte, error) {
+	data, err := cborEncMode.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(bytes.Clone(cborSelfDescribeTag), data...), nil
+}
+
+func (cborCodec) Unmarshal(data []byte, v any) error {
+	// the self describe tag is optional on receive
+	return cborDecMode.Unmarshal(bytes.TrimPrefix(data, cborSelfDescribeTag), v)
+}
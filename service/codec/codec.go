@@ -0,0 +1,70 @@
+// Package codec provides the payload codecs for NetworkMessages.
+// JSON is the default defined by the OI4 guideline. CBOR and gzip compressed JSON reduce the payload size,
+// they are recognized on receive by their leading marker bytes, so plain JSON payloads are decoded unchanged.
+package codec
+
+import (
+	"bytes"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+)
+
+const (
+	ContentTypeJson     = "application/json"
+	ContentTypeCbor     = "application/cbor"
+	ContentTypeGzipJson = "application/json+gzip"
+)
+
+var (
+	// cborSelfDescribeTag is the CBOR tag 55799, which marks a payload as CBOR (RFC 8949 3.4.6)
+	cborSelfDescribeTag = []byte{0xd9, 0xd9, 0xf7}
+	// gzipMagic starts every gzip stream (RFC 1952 2.3.1)
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+var (
+	Json     api.Codec = jsonCodec{}
+	Cbor     api.Codec = cborCodec{}
+	GzipJson api.Codec = gzipJsonCodec{}
+)
+
+// Default is the codec used if none is configured
+var Default = Json
+
+// OrDefault returns the codec or the Default codec if it is nil
+func OrDefault(codec api.Codec) api.Codec {
+	if codec == nil {
+		return Default
+	}
+	return codec
+}
+
+// Detect returns the codec a payload was encoded with, payloads without a known marker are JSON
+func Detect(payload []byte) api.Codec {
+	switch {
+	case bytes.HasPrefix(payload, cborSelfDescribeTag):
+		return Cbor
+	case bytes.HasPrefix(payload, gzipMagic):
+		return GzipJson
+	default:
+		return Json
+	}
+}
+
+// Unmarshal decodes a payload with the codec detected from its marker
+func Unmarshal(payload []byte, v any) error {
+	return Detect(payload).Unmarshal(payload, v)
+}
+
+// ForContentType returns the codec of a content type or false if it is unknown
+func ForContentType(contentType string) (api.Codec, bool) {
+	switch contentType {
+	case ContentTypeJson:
+		return Json, true
+	case ContentTypeCbor:
+		return Cbor, true
+	case ContentTypeGzipJson:
+		return GzipJson, true
+	default:
+		return nil, false
+	}
+}
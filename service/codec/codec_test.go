@@ -0,0 +1,78 @@
+package codec
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testNetworkMessage() *api.NetworkMessage {
+	timestamp := "2024-05-01T10:00:00Z"
+	correlationId := "1714557600000-Registry/acme.com///1"
+	return &api.NetworkMessage{
+		MessageId:     "1714557600001-Utility/acme.com///2",
+		MessageType:   api.UA_DATA,
+		PublisherId:   "Utility/acme.com///2",
+		CorrelationId: &correlationId,
+		Messages: []*api.DataSetMessage{
+			{
+				DataSetWriterId: 10,
+				Timestamp:       &timestamp,
+				Filter:          "temperature",
+				Source:          "acme.com///2",
+				Payload:         map[string]any{"Pv": 21.5, "Unit": "C"},
+			},
+		},
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for _, codec := range []api.Codec{Json, Cbor, GzipJson} {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			expected := testNetworkMessage()
+			payload, err := codec.Marshal(expected)
+			require.NoError(t, err)
+
+			assert.Equal(t, codec, Detect(payload))
+
+			decoded := api.NetworkMessage{}
+			require.NoError(t, Unmarshal(payload, &decoded))
+			assert.Equal(t, *expected, decoded)
+		})
+	}
+}
+
+func TestCborIsSmallerThanJson(t *testing.T) {
+	jsonPayload, err := Json.Marshal(testNetworkMessage())
+	require.NoError(t, err)
+	cborPayload, err := Cbor.Marshal(testNetworkMessage())
+	require.NoError(t, err)
+
+	assert.Less(t, len(cborPayload), len(jsonPayload))
+}
+
+func TestDetectJsonByDefault(t *testing.T) {
+	assert.Equal(t, Json, Detect([]byte(`{"MessageId":"1"}`)))
+	assert.Equal(t, Json, Detect(nil))
+}
+
+func TestCborWithoutSelfDescribeTag(t *testing.T) {
+	payload, err := Cbor.Marshal(map[string]any{"Pv": uint64(1)})
+	require.NoError(t, err)
+
+	decoded := map[string]any{}
+	require.NoError(t, Cbor.Unmarshal(payload[len(cborSelfDescribeTag):], &decoded))
+	assert.Equal(t, map[string]any{"Pv": uint64(1)}, decoded)
+}
+
+func TestForContentType(t *testing.T) {
+	for _, codec := range []api.Codec{Json, Cbor, GzipJson} {
+		found, ok := ForContentType(codec.ContentType())
+		assert.True(t, ok)
+		assert.Equal(t, codec, found)
+	}
+
+	_, ok := ForContentType("text/plain")
+	assert.False(t, ok)
+}
@@ -134,6 +134,24 @@ type BrokerConfiguration struct {
 	// The minimum value of MaxPacketSize is 262,144 bytes (256 kiB), the theoretical maximum is INT32max.
 	// The intention of the MaxPacketSize is to protect the Message Bus broker and client.
 	MaxPacketSize int32
+
+	// Optional transport of the broker, "tcp" (default) or "ws" for MQTT over secure WebSockets.
+	Transport string `json:",omitempty"`
+
+	// Optional path of the WebSocket endpoint, e.g. /mqtt.
+	Path string `json:",omitempty"`
+
+	// Optional redundant brokers, which are tried in the given order if the broker defined above is not reachable.
+	// This is an extension of the OI4 guideline, configurations without endpoints stay valid.
+	Endpoints []BrokerEndpoint `json:",omitempty"`
+}
+
+// BrokerEndpoint is a redundant broker of the BrokerConfiguration
+type BrokerEndpoint struct {
+	Address    string
+	SecurePort uint16
+	Transport  string `json:",omitempty"`
+	Path       string `json:",omitempty"`
 }
 
 func NewMessageBusStorage(folderPath string, logger *zap.SugaredLogger) (*MessageBusStorage, error) {
@@ -193,9 +211,30 @@ func parseBrokerConfiguration(configPath string, logger *zap.SugaredLogger) (*Br
 		}
 	}
 
+	if err = validateTransport(configuration.Transport); err != nil {
+		return nil, err
+	}
+	for _, endpoint := range configuration.Endpoints {
+		if endpoint.Address == "" || endpoint.SecurePort == 0 {
+			return nil, errors.New("invalid broker endpoint: address and secure port are required")
+		}
+		if err = validateTransport(endpoint.Transport); err != nil {
+			return nil, err
+		}
+	}
+
 	return &configuration, nil
 }
 
+func validateTransport(transport string) error {
+	switch transport {
+	case "", "tcp", "ws":
+		return nil
+	default:
+		return fmt.Errorf("invalid broker transport %s", transport)
+	}
+}
+
 // ****************************************************************
 // ***                                 Oi4CertificateStorage                                 ***
 // ****************************************************************
@@ -251,6 +290,7 @@ type SecretStorage struct {
 	// If present, it contains a username and password, which is used for MQTT authentication
 	MqttCredentials *url.Userinfo
 	// If present, it contains the private PEM encoded key to the according client certificate in the OI4 certificate store.
+	// The key is either a plain or an encrypted PKCS#8 key, which is decrypted with the MqttPassphrase.
 	MqttPrivateKey *pem.Block
 	// If present, it contains the base64 encoded passphrase for the private key.
 	MqttPassphrase *string
@@ -374,7 +414,7 @@ func readPrivateKey(pemBytes []byte) (*pem.Block, error) {
 	if block == nil {
 		return nil, errors.New("invalid private key: not in PEM format")
 	}
-	if block.Type != "PRIVATE KEY" {
+	if block.Type != "PRIVATE KEY" && block.Type != "ENCRYPTED PRIVATE KEY" {
 		return nil, errors.New(fmt.Sprintf("invalid private key: invalid type %s", block.Type))
 	}
 	return block, nil
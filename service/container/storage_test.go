@@ -335,3 +335,27 @@ func getLogger() *zap.SugaredLogger {
 
 	return logger.Sugar()
 }
+
+func TestParseBrokerConfiguration_Endpoints(t *testing.T) {
+	configuration, err := parseBrokerConfiguration(filepath.Join("testdata", "broker_endpoints", "broker.json"), getLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "oi4-oec-broker", configuration.Address)
+	assert.Equal(t, []BrokerEndpoint{
+		{Address: "oi4-oec-broker-2", SecurePort: 8883},
+		{Address: "proxy.example.com", SecurePort: 443, Transport: "ws", Path: "/mqtt"},
+	}, configuration.Endpoints)
+}
+
+func TestParseBrokerConfiguration_WithoutEndpoints(t *testing.T) {
+	configuration, err := parseBrokerConfiguration(filepath.Join("testdata", "valid", DefaultMessageBusStorageSubFolder, "broker.json"), getLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "oi4-oec-broker", configuration.Address)
+	assert.Empty(t, configuration.Endpoints)
+}
+
+func TestParseBrokerConfiguration_InvalidTransport(t *testing.T) {
+	configuration, err := parseBrokerConfiguration(filepath.Join("testdata", "broker_invalid_transport", "broker.json"), getLogger())
+	require.Error(t, err)
+	assert.Nil(t, configuration)
+	assert.Equal(t, "invalid broker transport quic", err.Error())
+}
@@ -0,0 +1,58 @@
+package mqtt
+
+import (
+	"fmt"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// BrokerUrls returns the URLs of the primary broker followed by the failover endpoints.
+// paho tries the brokers in this order on every connect and reconnect.
+func BrokerUrls(options *api.MqttClientOptions) ([]string, error) {
+	endpoints := append([]api.BrokerEndpoint{{
+		Host:      options.Host,
+		Port:      options.Port,
+		Transport: options.Transport,
+		Path:      options.Path,
+	}}, options.FailoverEndpoints...)
+
+	urls := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		brokerUrl, err := brokerUrl(endpoint, options.Tls)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, brokerUrl)
+	}
+	return urls, nil
+}
+
+func brokerUrl(endpoint api.BrokerEndpoint, secure bool) (string, error) {
+	var scheme string
+	switch endpoint.Transport {
+	case "", api.TransportTcp:
+		scheme = "tcp"
+		if secure {
+			scheme = "ssl"
+		}
+	case api.TransportWebSocket:
+		scheme = "ws"
+		if secure {
+			scheme = "wss"
+		}
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedTransport, endpoint.Transport)
+	}
+
+	brokerUrl := url.URL{
+		Scheme: scheme,
+		Host:   net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port)),
+	}
+	if endpoint.Path != "" && endpoint.Transport == api.TransportWebSocket {
+		brokerUrl.Path = "/" + strings.TrimPrefix(endpoint.Path, "/")
+	}
+	return brokerUrl.String(), nil
+}
@@ -0,0 +1,37 @@
+package mqtt
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBrokerUrls(t *testing.T) {
+	options := &api.MqttClientOptions{
+		Host: "oi4-oec-broker",
+		Port: 8883,
+		Tls:  true,
+		FailoverEndpoints: []api.BrokerEndpoint{
+			{Host: "oi4-oec-broker-2", Port: 8883},
+			{Host: "proxy.example.com", Port: 443, Transport: api.TransportWebSocket, Path: "mqtt"},
+		},
+	}
+
+	urls, err := BrokerUrls(options)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ssl://oi4-oec-broker:8883", "ssl://oi4-oec-broker-2:8883", "wss://proxy.example.com:443/mqtt"}, urls)
+}
+
+func TestBrokerUrlsWithoutTls(t *testing.T) {
+	options := &api.MqttClientOptions{Host: "localhost", Port: 1883, FailoverEndpoints: []api.BrokerEndpoint{{Host: "::1", Port: 8080, Transport: api.TransportWebSocket}}}
+
+	urls, err := BrokerUrls(options)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tcp://localhost:1883", "ws://[::1]:8080"}, urls)
+}
+
+func TestBrokerUrlsUnsupportedTransport(t *testing.T) {
+	_, err := BrokerUrls(&api.MqttClientOptions{Host: "localhost", Port: 1883, Transport: "quic"})
+	assert.ErrorIs(t, err, ErrUnsupportedTransport)
+}
@@ -0,0 +1,42 @@
+package mqtt
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"sync"
+)
+
+// ConnectionListeners notifies the registered listeners about connection state changes
+type ConnectionListeners struct {
+	listeners []api.ConnectionListener
+	mutex     sync.RWMutex
+}
+
+func NewConnectionListeners(listeners ...api.ConnectionListener) *ConnectionListeners {
+	result := &ConnectionListeners{listeners: make([]api.ConnectionListener, 0)}
+	for _, listener := range listeners {
+		result.Add(listener)
+	}
+	return result
+}
+
+func (l *ConnectionListeners) Add(listener api.ConnectionListener) {
+	if listener == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.listeners = append(l.listeners, listener)
+}
+
+func (l *ConnectionListeners) Notify(state api.ConnectionState, err error) {
+	l.mutex.RLock()
+	listeners := make([]api.ConnectionListener, len(l.listeners))
+	copy(listeners, l.listeners)
+	l.mutex.RUnlock()
+
+	for _, listener := range listeners {
+		listener(state, err)
+	}
+}
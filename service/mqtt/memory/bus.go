@@ -0,0 +1,139 @@
+// Package memory provides an in-process implementation of api.MqttClient for integration tests.
+// All clients created from the same Bus exchange their messages without a broker, supporting wildcard subscriptions,
+// QoS, retained messages and the last will.
+package memory
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/mqtt"
+	"sort"
+	"sync"
+)
+
+// Bus is a shared in-memory message broker
+type Bus struct {
+	clients  map[*Client]struct{}
+	retained map[string]*message
+	mutex    sync.RWMutex
+
+	// the members of a shared subscription receive the messages round-robin, ordered by their client id
+	nextClientId  uint64
+	sharedCounter map[string]int
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		clients:       make(map[*Client]struct{}),
+		retained:      make(map[string]*message),
+		sharedCounter: make(map[string]int),
+	}
+}
+
+// NewClient creates a connected client on the bus, it can be used with application.WithMqttClientFn
+func (bus *Bus) NewClient(options *api.MqttClientOptions) (api.MqttClient, error) {
+	bus.mutex.Lock()
+	bus.nextClientId++
+	client := newClient(bus, bus.nextClientId, options)
+	bus.mutex.Unlock()
+
+	if err := client.connect(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Retained returns the payload of the retained message of a topic or nil if there is none
+func (bus *Bus) Retained(topic string) []byte {
+	bus.mutex.RLock()
+	defer bus.mutex.RUnlock()
+
+	if retained, ok := bus.retained[topic]; ok {
+		return retained.payload
+	}
+	return nil
+}
+
+func (bus *Bus) addClient(client *Client) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	bus.clients[client] = struct{}{}
+}
+
+func (bus *Bus) removeClient(client *Client) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	delete(bus.clients, client)
+}
+
+func (bus *Bus) publish(msg *message) {
+	bus.mutex.Lock()
+	if msg.retained {
+		if len(msg.payload) == 0 {
+			delete(bus.retained, msg.topic)
+		} else {
+			bus.retained[msg.topic] = msg
+		}
+	}
+	clients := make([]*Client, 0, len(bus.clients))
+	for client := range bus.clients {
+		clients = append(clients, client)
+	}
+	bus.mutex.Unlock()
+
+	// an empty retained message only clears the retained message and is not forwarded
+	if msg.retained && len(msg.payload) == 0 {
+		return
+	}
+
+	// messages are forwarded as non retained to existing subscribers, like a broker does
+	forwarded := *msg
+	forwarded.retained = false
+	for _, client := range clients {
+		client.deliver(&forwarded)
+	}
+	bus.deliverShared(clients, &forwarded)
+}
+
+type sharedMember struct {
+	client *Client
+	sub    subscription
+}
+
+// deliverShared forwards a message to one member of every matching shared subscription
+func (bus *Bus) deliverShared(clients []*Client, msg *message) {
+	members := make(map[string][]sharedMember)
+	for _, client := range clients {
+		for filter, sub := range client.sharedSubscriptions(msg.topic) {
+			members[filter] = append(members[filter], sharedMember{client: client, sub: sub})
+		}
+	}
+
+	for filter, group := range members {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].client.id < group[j].client.id
+		})
+
+		bus.mutex.Lock()
+		member := group[bus.sharedCounter[filter]%len(group)]
+		bus.sharedCounter[filter]++
+		bus.mutex.Unlock()
+
+		member.sub.handler(nil, msg.withQos(min(msg.qos, member.sub.qos)))
+	}
+}
+
+// retainedMessages returns all retained messages matching the filter
+func (bus *Bus) retainedMessages(filter string) []*message {
+	bus.mutex.RLock()
+	defer bus.mutex.RUnlock()
+
+	result := make([]*message, 0)
+	for topic, retained := range bus.retained {
+		if mqtt.TopicMatches(filter, topic) {
+			result = append(result, retained)
+		}
+	}
+	return result
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type recorder struct {
+	messages []paho.Message
+}
+
+func (r *recorder) GetHandler() paho.MessageHandler {
+	return func(_ paho.Client, message paho.Message) {
+		r.messages = append(r.messages, message)
+	}
+}
+
+func (r *recorder) topics() []string {
+	result := make([]string, len(r.messages))
+	for i, message := range r.messages {
+		result[i] = message.Topic()
+	}
+	return result
+}
+
+func newTestClient(t *testing.T, bus *Bus, options *api.MqttClientOptions) *Client {
+	client, err := bus.NewClient(options)
+	require.NoError(t, err)
+	return client.(*Client)
+}
+
+func TestBusWildcardSubscription(t *testing.T) {
+	bus := NewBus()
+	publisher := newTestClient(t, bus, &api.MqttClientOptions{})
+	subscriber := newTestClient(t, bus, &api.MqttClientOptions{})
+
+	health := &recorder{}
+	require.NoError(t, subscriber.SubscribeToTopic("Oi4/+/+/+/+/+/Pub/Health/#", 1, health))
+
+	require.NoError(t, publisher.PublishResource("Oi4/Registry/a/b/c/d/Pub/Health/a/b/c/d", 1, false, "health"))
+	require.NoError(t, publisher.PublishResource("Oi4/Registry/a/b/c/d/Pub/MAM/a/b/c/d", 1, false, "mam"))
+
+	assert.Equal(t, []string{"Oi4/Registry/a/b/c/d/Pub/Health/a/b/c/d"}, health.topics())
+	assert.Equal(t, []byte(`"health"`), health.messages[0].Payload())
+}
+
+func TestBusQos(t *testing.T) {
+	bus := NewBus()
+	client := newTestClient(t, bus, &api.MqttClientOptions{})
+
+	received := &recorder{}
+	require.NoError(t, client.SubscribeToTopic("topic", 0, received))
+	require.NoError(t, client.PublishResource("topic", 1, false, "data"))
+
+	require.Len(t, received.messages, 1)
+	assert.Equal(t, byte(0), received.messages[0].Qos())
+}
+
+func TestBusRetainedMessages(t *testing.T) {
+	bus := NewBus()
+	publisher := newTestClient(t, bus, &api.MqttClientOptions{})
+	require.NoError(t, publisher.PublishResource("Oi4/Registry/a/b/c/d/Pub/MAM", 1, true, "mam"))
+
+	late := &recorder{}
+	subscriber := newTestClient(t, bus, &api.MqttClientOptions{})
+	require.NoError(t, subscriber.SubscribeToTopic("Oi4/#", 1, late))
+	require.Len(t, late.messages, 1)
+	assert.True(t, late.messages[0].Retained())
+
+	require.NoError(t, publisher.ClearRetained("Oi4/Registry/a/b/c/d/Pub/MAM"))
+	assert.Nil(t, bus.Retained("Oi4/Registry/a/b/c/d/Pub/MAM"))
+	assert.Len(t, late.messages, 1, "clearing a retained message is not forwarded")
+}
+
+func TestBusLastWillAndReconnect(t *testing.T) {
+	bus := NewBus()
+	states := make([]api.ConnectionState, 0)
+	client := newTestClient(t, bus, &api.MqttClientOptions{
+		WillFn: func() (*api.MqttWill, error) {
+			return &api.MqttWill{Topic: "will", Qos: 1, Retained: true, Payload: "gone"}, nil
+		},
+		ConnectionListener: func(state api.ConnectionState, _ error) {
+			states = append(states, state)
+		},
+	})
+	observer := newTestClient(t, bus, &api.MqttClientOptions{})
+	received := &recorder{}
+	require.NoError(t, observer.SubscribeToTopic("#", 1, received))
+
+	client.SimulateConnectionLoss()
+	assert.False(t, client.IsConnected())
+	assert.Equal(t, []byte(`"gone"`), bus.Retained("will"))
+
+	// publications are buffered while offline
+	require.NoError(t, client.PublishResource("data", 1, false, "buffered"))
+	assert.Equal(t, []string{"will"}, received.topics())
+
+	require.NoError(t, client.Reconnect())
+	assert.Equal(t, []api.ConnectionState{api.Connected, api.ConnectionLost, api.Reconnecting, api.Reconnected}, states)
+	assert.Equal(t, []string{"will", "data"}, received.topics())
+
+	client.Stop()
+	assert.Len(t, received.messages, 2, "a graceful stop does not publish the will")
+}
+
+func TestBusSharedSubscription(t *testing.T) {
+	bus := NewBus()
+	publisher := newTestClient(t, bus, &api.MqttClientOptions{})
+	first := newTestClient(t, bus, &api.MqttClientOptions{})
+	second := newTestClient(t, bus, &api.MqttClientOptions{})
+
+	require.NoError(t, publisher.PublishResource("Oi4/Registry/a/b/c/d/Pub/Data/a/b/c/d", 1, true, "retained"))
+
+	firstReceived := &recorder{}
+	secondReceived := &recorder{}
+	all := &recorder{}
+	require.NoError(t, first.SubscribeToTopic("$share/consumers/Oi4/+/+/+/+/+/Pub/Data/#", 1, firstReceived))
+	require.NoError(t, second.SubscribeToTopic("$share/consumers/Oi4/+/+/+/+/+/Pub/Data/#", 1, secondReceived))
+	require.NoError(t, second.SubscribeToTopic("Oi4/+/+/+/+/+/Pub/Data/#", 1, all))
+	// shared subscriptions receive no retained messages
+	assert.Empty(t, firstReceived.messages)
+	assert.Empty(t, secondReceived.messages)
+	require.Len(t, all.messages, 1)
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, publisher.PublishResource("Oi4/Registry/a/b/c/d/Pub/Data/a/b/c/d", 1, false, i))
+	}
+
+	assert.Len(t, firstReceived.messages, 2)
+	assert.Len(t, secondReceived.messages, 2)
+	assert.Len(t, all.messages, 5)
+	assert.Equal(t, "Oi4/Registry/a/b/c/d/Pub/Data/a/b/c/d", firstReceived.messages[0].Topic())
+
+	assert.ErrorIs(t, first.SubscribeToTopic("$share/+/Oi4/#", 1, firstReceived), tp.ErrInvalidShareGroup)
+}
@@ -0,0 +1,264 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/codec"
+	"github.com/OI4/oi4-oec-service-go/service/mqtt"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrClientStopped  = errors.New("client is stopped")
+	ErrConnectionLost = errors.New("simulated connection loss")
+)
+
+type subscription struct {
+	qos     byte
+	handler paho.MessageHandler
+}
+
+// Client is an api.MqttClient connected to an in-memory Bus
+type Client struct {
+	id      uint64
+	bus     *Bus
+	options *api.MqttClientOptions
+	codec   api.Codec
+
+	subscriptions     map[string]subscription
+	subscriptionMutex sync.RWMutex
+
+	connected     bool
+	stopped       bool
+	will          *api.MqttWill
+	offlineBuffer *mqtt.OfflineBuffer
+	stateMutex    sync.RWMutex
+
+	connectedBefore     bool
+	connectionListeners *mqtt.ConnectionListeners
+}
+
+func newClient(bus *Bus, id uint64, options *api.MqttClientOptions) *Client {
+	bufferSize := options.OfflineBufferSize
+	if bufferSize == 0 {
+		bufferSize = mqtt.DefaultOfflineBufferSize
+	}
+
+	return &Client{
+		id:            id,
+		bus:           bus,
+		options:       options,
+		codec:         codec.OrDefault(options.Codec),
+		subscriptions: make(map[string]subscription),
+		offlineBuffer: mqtt.NewOfflineBuffer(bufferSize, options.OfflineDropPolicy),
+
+		connectionListeners: mqtt.NewConnectionListeners(options.ConnectionListener),
+	}
+}
+
+// PublishResource publishes the encoded data, the MQTT 5 properties of the options are forwarded to the subscribers
+func (client *Client) PublishResource(topic string, qos byte, retained bool, data interface{}, opts ...api.PublishOption) error {
+	payload, err := client.codec.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return client.publish(mqtt.BufferedMessage{Topic: topic, Qos: qos, Retained: retained, Payload: payload, Properties: api.NewMessageProperties(opts...)})
+}
+
+func (client *Client) ClearRetained(topic string) error {
+	return client.publish(mqtt.BufferedMessage{Topic: topic, Qos: 1, Retained: true, Payload: []byte{}})
+}
+
+func (client *Client) RegisterGetHandler(serviceType api.ServiceType, appId api.Oi4Identifier, qos byte, handler api.MessageHandler) error {
+	topic := fmt.Sprintf("Oi4/%s/%s/Get/#", serviceType, appId.ToString())
+	return client.SubscribeToTopic(topic, qos, handler)
+}
+
+func (client *Client) Subscribe(subscription api.Subscription) error {
+	return client.SubscribeToTopic(subscription.GetTopic(), subscription.GetQoS(), subscription.GetHandler())
+}
+
+func (client *Client) SubscribeToTopic(topic string, qos byte, handler api.MessageHandler) error {
+	if _, _, err := tp.SplitShared(topic); err != nil {
+		return err
+	}
+
+	client.subscriptionMutex.Lock()
+	client.subscriptions[topic] = subscription{qos: qos, handler: handler.GetHandler()}
+	client.subscriptionMutex.Unlock()
+
+	if client.IsConnected() {
+		client.deliverRetained(topic, qos, handler.GetHandler())
+	}
+	return nil
+}
+
+func (client *Client) Unsubscribe(topic string) error {
+	client.subscriptionMutex.Lock()
+	defer client.subscriptionMutex.Unlock()
+
+	delete(client.subscriptions, topic)
+	return nil
+}
+
+func (client *Client) IsConnected() bool {
+	client.stateMutex.RLock()
+	defer client.stateMutex.RUnlock()
+
+	return client.connected
+}
+
+// AddConnectionListener registers a listener for the connection state changes after the initial connect
+func (client *Client) AddConnectionListener(listener api.ConnectionListener) {
+	client.connectionListeners.Add(listener)
+}
+
+// Stop disconnects the client gracefully, the last will is not published
+func (client *Client) Stop() {
+	client.stateMutex.Lock()
+	client.connected = false
+	client.stopped = true
+	client.stateMutex.Unlock()
+
+	client.bus.removeClient(client)
+}
+
+// SimulateConnectionLoss disconnects the client ungracefully, so the bus publishes the last will.
+// Publications are buffered until Reconnect is called.
+func (client *Client) SimulateConnectionLoss() {
+	client.stateMutex.Lock()
+	if !client.connected {
+		client.stateMutex.Unlock()
+		return
+	}
+	client.connected = false
+	will := client.will
+	client.stateMutex.Unlock()
+
+	client.bus.removeClient(client)
+
+	if will != nil {
+		if payload, err := client.codec.Marshal(will.Payload); err == nil {
+			client.bus.publish(newMessage(mqtt.BufferedMessage{Topic: will.Topic, Qos: will.Qos, Retained: will.Retained, Payload: payload}))
+		}
+	}
+	client.connectionListeners.Notify(api.ConnectionLost, ErrConnectionLost)
+}
+
+// Reconnect restores a connection lost by SimulateConnectionLoss, like an automatic reconnect of a real client
+func (client *Client) Reconnect() error {
+	client.connectionListeners.Notify(api.Reconnecting, nil)
+	return client.connect()
+}
+
+func (client *Client) connect() error {
+	client.stateMutex.Lock()
+	if client.stopped {
+		client.stateMutex.Unlock()
+		return ErrClientStopped
+	}
+
+	if client.options.WillFn != nil {
+		will, err := client.options.WillFn()
+		if err != nil {
+			client.stateMutex.Unlock()
+			return err
+		}
+		client.will = will
+	}
+	client.connected = true
+	client.stateMutex.Unlock()
+
+	client.bus.addClient(client)
+
+	client.subscriptionMutex.RLock()
+	subscriptions := make(map[string]subscription, len(client.subscriptions))
+	for topic, sub := range client.subscriptions {
+		subscriptions[topic] = sub
+	}
+	client.subscriptionMutex.RUnlock()
+
+	for topic, sub := range subscriptions {
+		client.deliverRetained(topic, sub.qos, sub.handler)
+	}
+
+	for _, buffered := range client.offlineBuffer.Drain() {
+		client.bus.publish(newMessage(buffered))
+	}
+
+	if client.connectedBefore {
+		client.connectionListeners.Notify(api.Reconnected, nil)
+	} else {
+		client.connectionListeners.Notify(api.Connected, nil)
+	}
+	client.connectedBefore = true
+	return nil
+}
+
+func (client *Client) publish(buffered mqtt.BufferedMessage) error {
+	if !client.IsConnected() {
+		if !client.offlineBuffer.Push(buffered) {
+			return mqtt.ErrOfflineBufferFull
+		}
+		return nil
+	}
+
+	client.bus.publish(newMessage(buffered))
+	return nil
+}
+
+// deliver forwards a message to every matching subscription of the client, except the shared subscriptions
+func (client *Client) deliver(msg *message) {
+	client.subscriptionMutex.RLock()
+	handlers := make([]subscription, 0)
+	for filter, sub := range client.subscriptions {
+		if !isShared(filter) && mqtt.TopicMatches(filter, msg.topic) {
+			handlers = append(handlers, sub)
+		}
+	}
+	client.subscriptionMutex.RUnlock()
+
+	for _, sub := range handlers {
+		sub.handler(nil, msg.withQos(min(msg.qos, sub.qos)))
+	}
+}
+
+// sharedSubscriptions returns the shared subscriptions of the client matching the topic
+func (client *Client) sharedSubscriptions(topic string) map[string]subscription {
+	client.subscriptionMutex.RLock()
+	defer client.subscriptionMutex.RUnlock()
+
+	result := make(map[string]subscription)
+	for filter, sub := range client.subscriptions {
+		if isShared(filter) && mqtt.TopicMatches(filter, topic) {
+			result[filter] = sub
+		}
+	}
+	return result
+}
+
+// deliverRetained sends the retained messages to a new subscription, shared subscriptions receive none like with MQTT 5
+func (client *Client) deliverRetained(filter string, qos byte, handler paho.MessageHandler) {
+	if isShared(filter) {
+		return
+	}
+
+	retained := client.bus.retainedMessages(filter)
+	sort.Slice(retained, func(i, j int) bool {
+		return retained[i].topic < retained[j].topic
+	})
+
+	for _, msg := range retained {
+		handler(nil, msg.withQos(min(msg.qos, qos)))
+	}
+}
+
+func isShared(filter string) bool {
+	group, _, _ := tp.SplitShared(filter)
+	return group != ""
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/mqtt"
+	"sync/atomic"
+)
+
+var messageIdCounter atomic.Uint32
+
+// message implements the paho mqtt.Message, so the message handlers of the application can be used unchanged
+type message struct {
+	id       uint16
+	topic    string
+	qos      byte
+	retained bool
+	payload  []byte
+
+	properties *api.MessageProperties
+}
+
+func newMessage(buffered mqtt.BufferedMessage) *message {
+	return &message{
+		id:       uint16(messageIdCounter.Add(1)),
+		topic:    buffered.Topic,
+		qos:      buffered.Qos,
+		retained: buffered.Retained,
+		payload:  buffered.Payload,
+
+		properties: buffered.Properties,
+	}
+}
+
+func (m *message) withQos(qos byte) *message {
+	result := *m
+	result.qos = qos
+	return &result
+}
+
+func (m *message) Duplicate() bool   { return false }
+func (m *message) Qos() byte         { return m.qos }
+func (m *message) Retained() bool    { return m.retained }
+func (m *message) Topic() string     { return m.topic }
+func (m *message) MessageID() uint16 { return m.id }
+func (m *message) Payload() []byte   { return m.payload }
+func (m *message) Ack()              {}
+
+// Properties returns the MQTT 5 properties of the message, as the bus supports them as well
+func (m *message) Properties() *api.MessageProperties {
+	return m.properties
+}
@@ -0,0 +1,351 @@
+// Package mqtt5 provides an api.MqttClient using MQTT 5. Besides the features of the MQTT 3.1.1 client it transmits
+// the CorrelationId of NetworkMessages as correlation data, sets the response topic of Get requests, supports a
+// message expiry per publication and provides the user properties of received messages.
+// The MQTT 3.1.1 client of the mqtt package stays the default, this client is selected with application.WithMqttClientFn.
+package mqtt5
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/codec"
+	"github.com/OI4/oi4-oec-service-go/service/mqtt"
+	"github.com/OI4/oi4-oec-service-go/service/tls"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	paho3 "github.com/eclipse/paho.mqtt.golang"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMaxReconnectInterval = 2 * time.Minute
+	minReconnectInterval        = time.Second
+	connectTimeout              = 30 * time.Second
+	requestTimeout              = 30 * time.Second
+	disconnectTimeout           = time.Second
+	keepAlive                   = 30
+)
+
+var ErrConnectTimeout = errors.New("timeout while connecting to the broker")
+
+type subscription struct {
+	qos     byte
+	handler paho3.MessageHandler
+}
+
+type Client struct {
+	manager *autopaho.ConnectionManager
+	codec   api.Codec
+
+	// subscriptions are restored after a reconnect, as every connection starts a new session
+	subscriptions     map[string]subscription
+	subscriptionMutex sync.RWMutex
+
+	offlineBuffer *mqtt.OfflineBuffer
+	flushMutex    sync.Mutex
+
+	connected           atomic.Bool
+	connectedBefore     atomic.Bool
+	connectionListeners *mqtt.ConnectionListeners
+}
+
+func NewClient(options *api.MqttClientOptions) (*Client, error) {
+	brokerUrls, err := mqtt.BrokerUrls(options)
+	if err != nil {
+		return nil, err
+	}
+	serverUrls := make([]*url.URL, 0, len(brokerUrls))
+	for _, brokerUrl := range brokerUrls {
+		serverUrl, err := url.Parse(brokerUrl)
+		if err != nil {
+			return nil, err
+		}
+		serverUrls = append(serverUrls, serverUrl)
+	}
+
+	bufferSize := options.OfflineBufferSize
+	if bufferSize == 0 {
+		bufferSize = mqtt.DefaultOfflineBufferSize
+	}
+
+	c := &Client{
+		codec:               codec.OrDefault(options.Codec),
+		subscriptions:       make(map[string]subscription),
+		offlineBuffer:       mqtt.NewOfflineBuffer(bufferSize, options.OfflineDropPolicy),
+		connectionListeners: mqtt.NewConnectionListeners(options.ConnectionListener),
+	}
+
+	config := autopaho.ClientConfig{
+		ServerUrls:                    serverUrls,
+		KeepAlive:                     keepAlive,
+		CleanStartOnInitialConnection: true,
+		ConnectTimeout:                connectTimeout,
+		ReconnectBackoff:              reconnectBackoff(options.MaxReconnectInterval),
+		OnConnectionUp: func(manager *autopaho.ConnectionManager, _ *paho.Connack) {
+			// the callback must not block
+			go c.onConnect(manager)
+		},
+		OnConnectionDown: func() bool {
+			c.connected.Store(false)
+			c.connectionListeners.Notify(api.ConnectionLost, nil)
+			return true
+		},
+		ConnectPacketBuilder: func(connect *paho.Connect, _ *url.URL) (*paho.Connect, error) {
+			if c.connectedBefore.Load() {
+				c.connectionListeners.Notify(api.Reconnecting, nil)
+			}
+			if options.WillFn != nil {
+				// keep the previous will if the new one cannot be created
+				_ = c.setWill(connect, options.WillFn)
+			}
+			return connect, nil
+		},
+		ClientConfig: paho.ClientConfig{
+			ClientID: options.ClientId,
+			OnPublishReceived: []func(paho.PublishReceived) (bool, error){
+				func(received paho.PublishReceived) (bool, error) {
+					c.route(received.Packet)
+					return true, nil
+				},
+			},
+		},
+	}
+
+	if options.Tls {
+		config.TlsCfg, err = tls.NewTLSConfig(options.Ca_certificate_pem, options.Client_certificate_pem, options.Client_private_key_pem, options.Client_private_key_passphrase, options.TlsVerify)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Username != "" && options.Password != "" {
+		config.ConnectUsername = options.Username
+		config.ConnectPassword = []byte(options.Password)
+	}
+
+	if options.WillFn != nil {
+		// the will is validated before connecting, afterward it is refreshed for every connection attempt
+		if err = c.setWill(&paho.Connect{}, options.WillFn); err != nil {
+			return nil, err
+		}
+	}
+
+	c.manager, err = autopaho.NewConnection(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	if err = c.manager.AwaitConnection(ctx); err != nil {
+		c.disconnect()
+		return nil, ErrConnectTimeout
+	}
+	return c, nil
+}
+
+// PublishResource publishes the encoded data with the MQTT 5 properties of the options.
+// The CorrelationId of a NetworkMessage is sent as correlation data, a Get request gets the Pub topic as response topic.
+func (client *Client) PublishResource(topic string, qos byte, retained bool, data interface{}, opts ...api.PublishOption) error {
+	payload, err := client.codec.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	properties := correlate(topic, data, api.NewMessageProperties(opts...))
+	if properties == nil {
+		properties = &api.MessageProperties{}
+	}
+	properties.ContentType = client.codec.ContentType()
+
+	return client.publish(mqtt.BufferedMessage{Topic: topic, Qos: qos, Retained: retained, Payload: payload, Properties: properties})
+}
+
+// ClearRetained removes the retained message of a topic by publishing an empty retained payload
+func (client *Client) ClearRetained(topic string) error {
+	return client.publish(mqtt.BufferedMessage{Topic: topic, Qos: 1, Retained: true, Payload: []byte{}})
+}
+
+func (client *Client) publish(message mqtt.BufferedMessage) error {
+	// the flush of the offline buffer is not interleaved, so a buffered message is never left behind by it
+	client.flushMutex.Lock()
+	if !client.IsConnected() {
+		defer client.flushMutex.Unlock()
+		if !client.offlineBuffer.Push(message) {
+			return mqtt.ErrOfflineBufferFull
+		}
+		return nil
+	}
+	client.flushMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	_, err := client.manager.Publish(ctx, toPublish(message))
+	return err
+}
+
+func (client *Client) RegisterGetHandler(serviceType api.ServiceType, appId api.Oi4Identifier, qos byte, handler api.MessageHandler) error {
+	topic := fmt.Sprintf("Oi4/%s/%s/Get/#", serviceType, appId.ToString())
+	return client.SubscribeToTopic(topic, qos, handler)
+}
+
+func (client *Client) Subscribe(subscription api.Subscription) error {
+	return client.SubscribeToTopic(subscription.GetTopic(), subscription.GetQoS(), subscription.GetHandler())
+}
+
+func (client *Client) SubscribeToTopic(topic string, qos byte, handler api.MessageHandler) error {
+	if _, _, err := tp.SplitShared(topic); err != nil {
+		return err
+	}
+
+	client.subscriptionMutex.Lock()
+	client.subscriptions[topic] = subscription{qos: qos, handler: handler.GetHandler()}
+	// onConnect collects the subscriptions and marks the client connected under the same lock
+	connected := client.IsConnected()
+	client.subscriptionMutex.Unlock()
+
+	if !connected {
+		// subscribed once the connection is restored
+		return nil
+	}
+	return client.subscribe(client.manager, topic, qos)
+}
+
+func (client *Client) Unsubscribe(topic string) error {
+	client.subscriptionMutex.Lock()
+	delete(client.subscriptions, topic)
+	client.subscriptionMutex.Unlock()
+
+	if !client.IsConnected() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	_, err := client.manager.Unsubscribe(ctx, &paho.Unsubscribe{Topics: []string{topic}})
+	return err
+}
+
+func (client *Client) IsConnected() bool {
+	return client.connected.Load()
+}
+
+// AddConnectionListener registers a listener for the connection state changes after the initial connect
+func (client *Client) AddConnectionListener(listener api.ConnectionListener) {
+	client.connectionListeners.Add(listener)
+}
+
+func (client *Client) Stop() {
+	client.disconnect()
+}
+
+func (client *Client) disconnect() {
+	ctx, cancel := context.WithTimeout(context.Background(), disconnectTimeout)
+	defer cancel()
+	_ = client.manager.Disconnect(ctx)
+	client.connected.Store(false)
+}
+
+func (client *Client) subscribe(manager *autopaho.ConnectionManager, topic string, qos byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	_, err := manager.Subscribe(ctx, &paho.Subscribe{
+		Subscriptions: []paho.SubscribeOptions{{Topic: topic, QoS: qos}},
+	})
+	return err
+}
+
+// onConnect is called on the initial connect and after every successful reconnect
+func (client *Client) onConnect(manager *autopaho.ConnectionManager) {
+	client.subscriptionMutex.Lock()
+	subscriptions := make(map[string]byte, len(client.subscriptions))
+	for topic, sub := range client.subscriptions {
+		subscriptions[topic] = sub.qos
+	}
+	// subscriptions added from now on are sent by SubscribeToTopic
+	client.connected.Store(true)
+	client.subscriptionMutex.Unlock()
+
+	for topic, qos := range subscriptions {
+		_ = client.subscribe(manager, topic, qos)
+	}
+
+	client.flushOfflineBuffer(manager)
+
+	if client.connectedBefore.Swap(true) {
+		client.connectionListeners.Notify(api.Reconnected, nil)
+	} else {
+		client.connectionListeners.Notify(api.Connected, nil)
+	}
+}
+
+func (client *Client) flushOfflineBuffer(manager *autopaho.ConnectionManager) {
+	client.flushMutex.Lock()
+	defer client.flushMutex.Unlock()
+
+	messages := client.offlineBuffer.Drain()
+	for i, message := range messages {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		_, err := manager.Publish(ctx, toPublish(message))
+		cancel()
+		if err != nil {
+			// connection was lost again, keep the remaining messages for the next reconnect
+			client.offlineBuffer.Requeue(messages[i:])
+			return
+		}
+	}
+}
+
+// route forwards a received message to every matching subscription. Like the router of the MQTT 3.1.1 client, a
+// message matching overlapping filters is passed to the handler of each filter. A broker sending a copy per matching
+// subscription, which MQTT 5 allows, delivers the message to these handlers once per copy.
+func (client *Client) route(publish *paho.Publish) {
+	msg := newMessage(publish)
+
+	client.subscriptionMutex.RLock()
+	handlers := make([]paho3.MessageHandler, 0)
+	for filter, sub := range client.subscriptions {
+		if mqtt.TopicMatches(filter, publish.Topic) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	client.subscriptionMutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(nil, msg)
+	}
+}
+
+func (client *Client) setWill(connect *paho.Connect, willFn func() (*api.MqttWill, error)) error {
+	will, err := willFn()
+	if err != nil {
+		return err
+	}
+	if will == nil {
+		connect.WillMessage = nil
+		connect.WillProperties = nil
+		return nil
+	}
+
+	payload, err := client.codec.Marshal(will.Payload)
+	if err != nil {
+		return err
+	}
+	connect.WillMessage = &paho.WillMessage{Topic: will.Topic, QoS: will.Qos, Retain: will.Retained, Payload: payload}
+	connect.WillProperties = &paho.WillProperties{ContentType: client.codec.ContentType()}
+	return nil
+}
+
+// reconnectBackoff doubles the reconnect interval starting at 1 second up to the maximum interval
+func reconnectBackoff(maxInterval time.Duration) autopaho.Backoff {
+	if maxInterval <= 0 {
+		maxInterval = defaultMaxReconnectInterval
+	}
+	maxInterval = max(maxInterval, 2*minReconnectInterval)
+	return autopaho.NewExponentialBackoff(minReconnectInterval, maxInterval, 2*minReconnectInterval, 2)
+}
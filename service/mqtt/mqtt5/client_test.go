@@ -0,0 +1,200 @@
+package mqtt5
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/eclipse/paho.golang/packets"
+	paho3 "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testBroker is a minimal MQTT 5 broker, which acknowledges the packets of a single client and records the topics
+// subscribed on its current connection
+type testBroker struct {
+	listener net.Listener
+
+	mutex      sync.Mutex
+	connection net.Conn
+	topics     []string
+	// onSubscribe is called for every received SUBSCRIBE before it is acknowledged
+	onSubscribe func(topics []string)
+}
+
+func startTestBroker(t *testing.T) *testBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	broker := &testBroker{listener: listener}
+	go broker.accept()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		broker.SimulateConnectionLoss()
+	})
+	return broker
+}
+
+func (broker *testBroker) options() *api.MqttClientOptions {
+	address := broker.listener.Addr().(*net.TCPAddr)
+	return &api.MqttClientOptions{Host: address.IP.String(), Port: address.Port, ClientId: "client", MaxReconnectInterval: 2 * time.Second}
+}
+
+func (broker *testBroker) accept() {
+	for {
+		connection, err := broker.listener.Accept()
+		if err != nil {
+			return
+		}
+		broker.mutex.Lock()
+		broker.connection = connection
+		broker.topics = nil
+		broker.mutex.Unlock()
+		go broker.serve(connection)
+	}
+}
+
+func (broker *testBroker) serve(connection net.Conn) {
+	defer func() { _ = connection.Close() }()
+	for {
+		packet, err := packets.ReadPacket(connection)
+		if err != nil {
+			return
+		}
+
+		var response *packets.ControlPacket
+		switch content := packet.Content.(type) {
+		case *packets.Connect:
+			response = packets.NewControlPacket(packets.CONNACK)
+		case *packets.Subscribe:
+			response = packets.NewControlPacket(packets.SUBACK)
+			suback := response.Content.(*packets.Suback)
+			suback.PacketID = content.PacketID
+			topics := make([]string, 0, len(content.Subscriptions))
+			for _, subscription := range content.Subscriptions {
+				broker.subscribed(connection, subscription.Topic)
+				suback.Reasons = append(suback.Reasons, subscription.QoS)
+				topics = append(topics, subscription.Topic)
+			}
+			broker.mutex.Lock()
+			onSubscribe := broker.onSubscribe
+			broker.mutex.Unlock()
+			if onSubscribe != nil {
+				onSubscribe(topics)
+			}
+		case *packets.Unsubscribe:
+			response = packets.NewControlPacket(packets.UNSUBACK)
+			unsuback := response.Content.(*packets.Unsuback)
+			unsuback.PacketID = content.PacketID
+			unsuback.Reasons = make([]byte, len(content.Topics))
+		case *packets.Publish:
+			if content.QoS == 1 {
+				response = packets.NewControlPacket(packets.PUBACK)
+				response.Content.(*packets.Puback).PacketID = content.PacketID
+			}
+		case *packets.Pingreq:
+			response = packets.NewControlPacket(packets.PINGRESP)
+		case *packets.Disconnect:
+			return
+		}
+
+		if response != nil {
+			if _, err = response.WriteTo(connection); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (broker *testBroker) subscribed(connection net.Conn, topic string) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if broker.connection == connection {
+		broker.topics = append(broker.topics, topic)
+	}
+}
+
+// Topics returns the topics subscribed on the current connection
+func (broker *testBroker) Topics() []string {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	return slices.Clone(broker.topics)
+}
+
+// OnSubscribe sets the function called for every received SUBSCRIBE before it is acknowledged
+func (broker *testBroker) OnSubscribe(onSubscribe func(topics []string)) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	broker.onSubscribe = onSubscribe
+}
+
+// SimulateConnectionLoss closes the current connection without a DISCONNECT
+func (broker *testBroker) SimulateConnectionLoss() {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if broker.connection != nil {
+		_ = broker.connection.Close()
+	}
+}
+
+type handlerFunc paho3.MessageHandler
+
+func (handler handlerFunc) GetHandler() paho3.MessageHandler {
+	return paho3.MessageHandler(handler)
+}
+
+func subscribeTestTopic(t *testing.T, client *Client, topic string) {
+	require.NoError(t, client.SubscribeToTopic(topic, 1, handlerFunc(func(paho3.Client, paho3.Message) {})))
+}
+
+func TestSubscribeRightAfterNewClient(t *testing.T) {
+	broker := startTestBroker(t)
+	client, err := NewClient(broker.options())
+	require.NoError(t, err)
+	t.Cleanup(client.Stop)
+
+	// the connection may not be marked as connected yet, the subscriptions must reach the broker anyway
+	for i := 0; i < 10; i++ {
+		subscribeTestTopic(t, client, "Oi4/Registry/acme.com///1/Get/"+strconv.Itoa(i))
+	}
+
+	assert.Eventually(t, func() bool { return len(broker.Topics()) == 10 }, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, broker.Topics(), 10)
+}
+
+func TestSubscribeAfterConnectionLoss(t *testing.T) {
+	broker := startTestBroker(t)
+	client, err := NewClient(broker.options())
+	require.NoError(t, err)
+	t.Cleanup(client.Stop)
+
+	subscribeTestTopic(t, client, "Oi4/Registry/acme.com///1/Get/#")
+	require.Eventually(t, client.IsConnected, 5*time.Second, 10*time.Millisecond)
+
+	// subscribes while the client restores the previous subscriptions after the reconnect
+	subscribed := make(chan struct{})
+	broker.OnSubscribe(func(topics []string) {
+		if slices.Contains(topics, "Oi4/Registry/acme.com///1/Get/#") && len(broker.Topics()) == 1 {
+			go func() {
+				subscribeTestTopic(t, client, "Oi4/Registry/acme.com///1/Set/#")
+				close(subscribed)
+			}()
+			// the SUBACK is sent after the subscription is registered, SubscribeToTopic may wait for the SUBACK
+			select {
+			case <-subscribed:
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	})
+	broker.SimulateConnectionLoss()
+
+	assert.Eventually(t, func() bool {
+		topics := broker.Topics()
+		return slices.Contains(topics, "Oi4/Registry/acme.com///1/Get/#") && slices.Contains(topics, "Oi4/Registry/acme.com///1/Set/#")
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.True(t, client.IsConnected())
+}
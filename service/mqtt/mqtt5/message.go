@@ -0,0 +1,151 @@
+package mqtt5
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/mqtt"
+	"github.com/eclipse/paho.golang/paho"
+	"strings"
+)
+
+// message adapts a MQTT 5 publication to the paho mqtt.Message, so the message handlers of the application can be
+// used unchanged. The MQTT 5 properties are provided with api.PropertiesMessage.
+type message struct {
+	publish    *paho.Publish
+	properties *api.MessageProperties
+}
+
+func newMessage(publish *paho.Publish) *message {
+	return &message{
+		publish:    publish,
+		properties: fromPublishProperties(publish.Properties),
+	}
+}
+
+func (m *message) Duplicate() bool   { return false }
+func (m *message) Qos() byte         { return m.publish.QoS }
+func (m *message) Retained() bool    { return m.publish.Retain }
+func (m *message) Topic() string     { return m.publish.Topic }
+func (m *message) MessageID() uint16 { return m.publish.PacketID }
+func (m *message) Payload() []byte   { return m.publish.Payload }
+func (m *message) Ack()              {}
+
+func (m *message) Properties() *api.MessageProperties {
+	return m.properties
+}
+
+func toPublish(message mqtt.BufferedMessage) *paho.Publish {
+	return &paho.Publish{
+		Topic:      message.Topic,
+		QoS:        message.Qos,
+		Retain:     message.Retained,
+		Payload:    message.Payload,
+		Properties: toPublishProperties(message.Properties),
+	}
+}
+
+func toPublishProperties(properties *api.MessageProperties) *paho.PublishProperties {
+	if properties == nil {
+		return nil
+	}
+
+	result := &paho.PublishProperties{
+		ContentType:     properties.ContentType,
+		CorrelationData: properties.CorrelationData,
+		ResponseTopic:   properties.ResponseTopic,
+		MessageExpiry:   properties.MessageExpiry,
+	}
+	for key, value := range properties.UserProperties {
+		result.User.Add(key, value)
+	}
+	return result
+}
+
+func fromPublishProperties(properties *paho.PublishProperties) *api.MessageProperties {
+	if properties == nil {
+		return nil
+	}
+
+	result := &api.MessageProperties{
+		ContentType:     properties.ContentType,
+		CorrelationData: properties.CorrelationData,
+		ResponseTopic:   properties.ResponseTopic,
+		MessageExpiry:   properties.MessageExpiry,
+	}
+	if len(properties.User) > 0 {
+		result.UserProperties = make(map[string]string, len(properties.User))
+		for _, property := range properties.User {
+			result.UserProperties[property.Key] = property.Value
+		}
+	}
+	return result
+}
+
+// correlate adds the correlation data and response topic of OI4 messages, unless they are already set by the options.
+// The correlation data always contains the id the response refers to: the MessageId of a Get or Call request or the
+// CorrelationId of a published NetworkMessage or Reply.
+func correlate(topic string, data interface{}, properties *api.MessageProperties) *api.MessageProperties {
+	var correlationId *string
+	responseTopic := ""
+
+	switch msg := data.(type) {
+	case *api.NetworkMessage:
+		correlationId = msg.CorrelationId
+	case api.NetworkMessage:
+		correlationId = msg.CorrelationId
+	case *api.GetMessage:
+		correlationId = &msg.MessageId
+		responseTopic = responseTopicOf(topic)
+	case api.GetMessage:
+		correlationId = &msg.MessageId
+		responseTopic = responseTopicOf(topic)
+	case *api.ServiceNetworkMessage:
+		correlationId, responseTopic = correlateService(topic, *msg)
+	case api.ServiceNetworkMessage:
+		correlationId, responseTopic = correlateService(topic, msg)
+	}
+
+	if (correlationId == nil || *correlationId == "") && responseTopic == "" {
+		return properties
+	}
+	if properties == nil {
+		properties = &api.MessageProperties{}
+	}
+	if properties.CorrelationData == nil && correlationId != nil && *correlationId != "" {
+		properties.CorrelationData = []byte(*correlationId)
+	}
+	if properties.ResponseTopic == "" {
+		properties.ResponseTopic = responseTopic
+	}
+	return properties
+}
+
+// correlateService returns the CorrelationId of a Reply, or the MessageId and the response topic of a Call request
+func correlateService(topic string, msg api.ServiceNetworkMessage) (*string, string) {
+	if msg.CorrelationId != "" {
+		return &msg.CorrelationId, ""
+	}
+	return &msg.MessageId, responseTopicOf(topic)
+}
+
+// responseMethods maps the methods of requests to the methods of their responses
+var responseMethods = map[string]api.MethodType{
+	string(api.MethodGet):  api.MethodPub,
+	string(api.MethodCall): api.MethodReply,
+}
+
+// responseTopicOf returns the topic answering a Get or Call topic: Oi4/<ServiceType>/<AppId>/Get/... → Oi4/<ServiceType>/<AppId>/Pub/...
+func responseTopicOf(topic string) string {
+	// the method follows the Oi4 prefix, the service type and the 4 levels of the application id
+	const methodLevel = 6
+
+	levels := strings.Split(topic, "/")
+	if len(levels) <= methodLevel {
+		return ""
+	}
+	method, ok := responseMethods[levels[methodLevel]]
+	if !ok {
+		return ""
+	}
+	levels[methodLevel] = string(method)
+	return strings.Join(levels, "/")
+}
@@ -0,0 +1,113 @@
+package mqtt5
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/mqtt"
+	"github.com/eclipse/paho.golang/paho"
+	paho3 "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCorrelateNetworkMessage(t *testing.T) {
+	correlationId := "1714557600000-Registry/acme.com///1"
+	networkMessage := &api.NetworkMessage{MessageId: "1714557600001-Utility/acme.com///2", CorrelationId: &correlationId}
+
+	properties := correlate("Oi4/Utility/acme.com///2/Pub/MAM/acme.com///2", networkMessage, nil)
+	require.NotNil(t, properties)
+	assert.Equal(t, []byte(correlationId), properties.CorrelationData)
+	assert.Empty(t, properties.ResponseTopic)
+
+	assert.Nil(t, correlate("Oi4/Utility/acme.com///2/Pub/MAM/acme.com///2", &api.NetworkMessage{}, nil))
+}
+
+func TestCorrelateGetRequest(t *testing.T) {
+	getMessage := api.GetMessage{MessageId: "1714557600000-Registry/acme.com///1"}
+
+	properties := correlate("Oi4/Utility/acme.com///2/Get/MAM/acme.com///2", getMessage, api.NewMessageProperties(api.WithUserProperty("tenant", "a")))
+	require.NotNil(t, properties)
+	assert.Equal(t, []byte(getMessage.MessageId), properties.CorrelationData)
+	assert.Equal(t, "Oi4/Utility/acme.com///2/Pub/MAM/acme.com///2", properties.ResponseTopic)
+	assert.Equal(t, map[string]string{"tenant": "a"}, properties.UserProperties)
+}
+
+func TestCorrelateServiceMessages(t *testing.T) {
+	call := api.ServiceNetworkMessage{MessageId: "1714557600000-Registry/acme.com///1"}
+	properties := correlate("Oi4/Utility/acme.com///2/Call/Blink/acme.com///2", call, nil)
+	require.NotNil(t, properties)
+	assert.Equal(t, []byte(call.MessageId), properties.CorrelationData)
+	assert.Equal(t, "Oi4/Utility/acme.com///2/Reply/Blink/acme.com///2", properties.ResponseTopic)
+
+	reply := &api.ServiceNetworkMessage{MessageId: "2", CorrelationId: call.MessageId}
+	properties = correlate("Oi4/Utility/acme.com///2/Reply/Blink/acme.com///2", reply, nil)
+	require.NotNil(t, properties)
+	assert.Equal(t, []byte(call.MessageId), properties.CorrelationData)
+	assert.Empty(t, properties.ResponseTopic)
+}
+
+func TestCorrelateKeepsExplicitProperties(t *testing.T) {
+	getMessage := &api.GetMessage{MessageId: "1"}
+
+	properties := correlate("Oi4/Utility/acme.com///2/Get/MAM", getMessage, api.NewMessageProperties(api.WithCorrelationData([]byte("2")), api.WithResponseTopic("reply")))
+	assert.Equal(t, []byte("2"), properties.CorrelationData)
+	assert.Equal(t, "reply", properties.ResponseTopic)
+}
+
+func TestResponseTopicOf(t *testing.T) {
+	assert.Equal(t, "Oi4/Registry/acme.com/model/code/1/Pub/Health", responseTopicOf("Oi4/Registry/acme.com/model/code/1/Get/Health"))
+	assert.Equal(t, "Oi4/Registry/acme.com/model/code/1/Reply/Blink", responseTopicOf("Oi4/Registry/acme.com/model/code/1/Call/Blink"))
+	assert.Empty(t, responseTopicOf("Oi4/Registry/acme.com/model/code/1/Pub/Health"))
+	assert.Empty(t, responseTopicOf("Oi4/Registry"))
+}
+
+func TestPublishPropertiesRoundTrip(t *testing.T) {
+	properties := api.NewMessageProperties(
+		api.WithMessageExpiry(1500*time.Millisecond),
+		api.WithUserProperty("site", "plant-1"),
+		api.WithCorrelationData([]byte("id")),
+	)
+	properties.ContentType = "application/json"
+
+	publish := toPublish(mqtt.BufferedMessage{Topic: "topic", Qos: 1, Payload: []byte("{}"), Properties: properties})
+	require.NotNil(t, publish.Properties.MessageExpiry)
+	assert.Equal(t, uint32(2), *publish.Properties.MessageExpiry, "the expiry is rounded up to full seconds")
+
+	received := newMessage(publish)
+	assert.Equal(t, "topic", received.Topic())
+	assert.Equal(t, byte(1), received.Qos())
+	assert.Equal(t, properties, received.Properties())
+}
+
+func TestMessageWithoutProperties(t *testing.T) {
+	received := newMessage(&paho.Publish{Topic: "topic"})
+	assert.Nil(t, received.Properties())
+	assert.Nil(t, toPublish(mqtt.BufferedMessage{Topic: "topic"}).Properties)
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	for _, maxInterval := range []time.Duration{0, time.Second, time.Minute} {
+		backoff := reconnectBackoff(maxInterval)
+		assert.Equal(t, time.Duration(0), backoff(0))
+		assert.GreaterOrEqual(t, backoff(1), minReconnectInterval)
+	}
+}
+
+func TestRoutePassesMessageToEveryMatchingSubscription(t *testing.T) {
+	received := make(map[string]int)
+	handler := func(filter string) paho3.MessageHandler {
+		return func(_ paho3.Client, message paho3.Message) {
+			assert.Equal(t, "Oi4/Utility/acme.com///2/Pub/MAM/acme.com///2", message.Topic())
+			received[filter]++
+		}
+	}
+	client := &Client{subscriptions: map[string]subscription{
+		"Oi4/#":                 {handler: handler("Oi4/#")},
+		"Oi4/Utility/+/+/+/+/#": {handler: handler("Oi4/Utility/+/+/+/+/#")},
+		"Oi4/Registry/#":        {handler: handler("Oi4/Registry/#")},
+	}}
+
+	client.route(&paho.Publish{Topic: "Oi4/Utility/acme.com///2/Pub/MAM/acme.com///2", Payload: []byte("{}")})
+	assert.Equal(t, map[string]int{"Oi4/#": 1, "Oi4/Utility/+/+/+/+/#": 1}, received)
+}
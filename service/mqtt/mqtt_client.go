@@ -1,35 +1,68 @@
 package mqtt
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/OI4/oi4-oec-service-go/service/codec"
 	"github.com/OI4/oi4-oec-service-go/service/tls"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
 	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"sync"
+	"time"
 )
 
 var (
-	ErrNoAuthInformation = errors.New("no auth information provided, please provide either a mTLS certificate or username/password")
+	ErrOfflineBufferFull    = errors.New("client is offline and the offline buffer is full, message dropped")
+	ErrUnsupportedTransport = errors.New("unsupported transport")
+	ErrRequestTimeout       = errors.New("timeout while waiting for the broker")
 )
 
+const (
+	defaultMaxReconnectInterval = 2 * time.Minute
+	// requestTimeout limits the wait for the acknowledgement of a subscribe or unsubscribe request
+	requestTimeout = 30 * time.Second
+)
+
+type subscription struct {
+	qos     byte
+	handler mqtt.MessageHandler
+}
+
 type Client struct {
 	client mqtt.Client
+	codec  api.Codec
+
+	// subscriptions are tracked to be restored after a reconnect, as the broker drops them with a clean session
+	subscriptions     map[string]subscription
+	subscriptionMutex sync.RWMutex
+
+	offlineBuffer *OfflineBuffer
+	flushMutex    sync.Mutex
+
+	connectedBefore     bool
+	connectionListeners *ConnectionListeners
 }
 
 func NewClient(options *api.MqttClientOptions) (*Client, error) {
 	clientOptions := mqtt.NewClientOptions()
 
 	clientOptions.SetClientID(options.ClientId)
+
+	brokerUrls, err := BrokerUrls(options)
+	if err != nil {
+		return nil, err
+	}
+	for _, brokerUrl := range brokerUrls {
+		clientOptions.AddBroker(brokerUrl)
+	}
+
 	if options.Tls {
-		clientOptions.AddBroker(fmt.Sprintf("ssl://%s:%d", options.Host, options.Port))
-		tlsConfig, err := tls.NewTLSConfig(options.Ca_certificate_pem, options.Client_certificate_pem, options.Client_private_key_pem, options.TlsVerify)
+		tlsConfig, err := tls.NewTLSConfig(options.Ca_certificate_pem, options.Client_certificate_pem, options.Client_private_key_pem, options.Client_private_key_passphrase, options.TlsVerify)
 		if err != nil {
 			return nil, err
 		}
 		clientOptions.SetTLSConfig(tlsConfig)
-	} else {
-		clientOptions.AddBroker(fmt.Sprintf("tcp://%s:%d", options.Host, options.Port))
 	}
 
 	if options.Username != "" && options.Password != "" {
@@ -37,22 +70,79 @@ func NewClient(options *api.MqttClientOptions) (*Client, error) {
 		clientOptions.Password = options.Password
 	}
 
+	maxReconnectInterval := options.MaxReconnectInterval
+	if maxReconnectInterval <= 0 {
+		maxReconnectInterval = defaultMaxReconnectInterval
+	}
+	clientOptions.SetAutoReconnect(true)
+	clientOptions.SetMaxReconnectInterval(maxReconnectInterval)
+
+	bufferSize := options.OfflineBufferSize
+	if bufferSize == 0 {
+		bufferSize = DefaultOfflineBufferSize
+	}
+
+	c := &Client{
+		codec:               codec.OrDefault(options.Codec),
+		subscriptions:       make(map[string]subscription),
+		offlineBuffer:       NewOfflineBuffer(bufferSize, options.OfflineDropPolicy),
+		connectionListeners: NewConnectionListeners(options.ConnectionListener),
+	}
+	clientOptions.SetOnConnectHandler(c.onConnect)
+	clientOptions.SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+		c.connectionListeners.Notify(api.ConnectionLost, err)
+	})
+
+	if options.WillFn != nil {
+		if err := setWill(clientOptions, options.WillFn, c.codec); err != nil {
+			return nil, err
+		}
+	}
+	clientOptions.SetReconnectingHandler(func(_ mqtt.Client, reconnectOptions *mqtt.ClientOptions) {
+		if options.WillFn != nil {
+			// keep the previous will if the new one cannot be created
+			_ = setWill(reconnectOptions, options.WillFn, c.codec)
+		}
+		c.connectionListeners.Notify(api.Reconnecting, nil)
+	})
+
 	client := mqtt.NewClient(clientOptions)
 
 	if token := client.Connect(); token.Wait() && token.Error() != nil {
 		return nil, token.Error()
-	} else {
-		return &Client{client: client}, nil
 	}
+	c.client = client
+	return c, nil
 }
 
-func (client *Client) PublishResource(topic string, qos byte, data interface{}) error {
-	marshalledString, err := json.Marshal(data)
+// PublishResource publishes the encoded data, the MQTT 5 options are not supported by MQTT 3.1.1 and ignored
+func (client *Client) PublishResource(topic string, qos byte, retained bool, data interface{}, _ ...api.PublishOption) error {
+	marshalledString, err := client.codec.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	if token := client.client.Publish(topic, 0, false, string(marshalledString)); token.Wait() && token.Error() != nil {
+	return client.publish(BufferedMessage{Topic: topic, Qos: qos, Retained: retained, Payload: marshalledString})
+}
+
+// ClearRetained removes the retained message of a topic by publishing an empty retained payload
+func (client *Client) ClearRetained(topic string) error {
+	return client.publish(BufferedMessage{Topic: topic, Qos: 1, Retained: true, Payload: []byte{}})
+}
+
+func (client *Client) publish(message BufferedMessage) error {
+	// the flush of the offline buffer is not interleaved, so a buffered message is never left behind by it
+	client.flushMutex.Lock()
+	if client.client == nil || !client.client.IsConnectionOpen() {
+		defer client.flushMutex.Unlock()
+		if !client.offlineBuffer.Push(message) {
+			return ErrOfflineBufferFull
+		}
+		return nil
+	}
+	client.flushMutex.Unlock()
+
+	if token := client.client.Publish(message.Topic, message.Qos, message.Retained, message.Payload); token.Wait() && token.Error() != nil {
 		return token.Error()
 	}
 	return nil
@@ -69,10 +159,99 @@ func (client *Client) Subscribe(subscription api.Subscription) error {
 }
 
 func (client *Client) SubscribeToTopic(topic string, qos byte, handler api.MessageHandler) error {
-	token := client.client.Subscribe(topic, qos, handler.GetHandler())
-	return token.Error()
+	if _, _, err := tp.SplitShared(topic); err != nil {
+		return err
+	}
+
+	client.subscriptionMutex.Lock()
+	client.subscriptions[topic] = subscription{qos: qos, handler: handler.GetHandler()}
+	client.subscriptionMutex.Unlock()
+
+	return waitForToken(client.client.Subscribe(topic, qos, handler.GetHandler()))
+}
+
+func (client *Client) Unsubscribe(topic string) error {
+	client.subscriptionMutex.Lock()
+	delete(client.subscriptions, topic)
+	client.subscriptionMutex.Unlock()
+
+	return waitForToken(client.client.Unsubscribe(topic))
+}
+
+func (client *Client) IsConnected() bool {
+	return client.client != nil && client.client.IsConnectionOpen()
+}
+
+// AddConnectionListener registers a listener for the connection state changes after the initial connect
+func (client *Client) AddConnectionListener(listener api.ConnectionListener) {
+	client.connectionListeners.Add(listener)
 }
 
 func (client *Client) Stop() {
 	client.client.Disconnect(1000)
 }
+
+// onConnect is called by paho on the initial connect and after every successful reconnect
+func (client *Client) onConnect(c mqtt.Client) {
+	client.subscriptionMutex.RLock()
+	subscriptions := make(map[string]subscription, len(client.subscriptions))
+	for topic, sub := range client.subscriptions {
+		subscriptions[topic] = sub
+	}
+	client.subscriptionMutex.RUnlock()
+
+	// a failed subscription is kept and restored with the next reconnect
+	for topic, sub := range subscriptions {
+		_ = waitForToken(c.Subscribe(topic, sub.qos, sub.handler))
+	}
+
+	client.flushOfflineBuffer(c)
+
+	if client.connectedBefore {
+		client.connectionListeners.Notify(api.Reconnected, nil)
+	} else {
+		client.connectionListeners.Notify(api.Connected, nil)
+	}
+	client.connectedBefore = true
+}
+
+func (client *Client) flushOfflineBuffer(c mqtt.Client) {
+	client.flushMutex.Lock()
+	defer client.flushMutex.Unlock()
+
+	messages := client.offlineBuffer.Drain()
+	for i, message := range messages {
+		token := c.Publish(message.Topic, message.Qos, message.Retained, message.Payload)
+		if token.Wait() && token.Error() != nil {
+			// connection was lost again, keep the remaining messages for the next reconnect
+			client.offlineBuffer.Requeue(messages[i:])
+			return
+		}
+	}
+}
+
+// waitForToken waits for the acknowledgement of a request, at most for requestTimeout
+func waitForToken(token mqtt.Token) error {
+	if !token.WaitTimeout(requestTimeout) {
+		return ErrRequestTimeout
+	}
+	return token.Error()
+}
+
+func setWill(clientOptions *mqtt.ClientOptions, willFn func() (*api.MqttWill, error), payloadCodec api.Codec) error {
+	will, err := willFn()
+	if err != nil {
+		return err
+	}
+	if will == nil {
+		clientOptions.UnsetWill()
+		return nil
+	}
+
+	payload, err := payloadCodec.Marshal(will.Payload)
+	if err != nil {
+		return err
+	}
+	clientOptions.SetBinaryWill(will.Topic, payload, will.Qos, will.Retained)
+	return nil
+}
@@ -0,0 +1,108 @@
+package mqtt
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"sync"
+)
+
+const DefaultOfflineBufferSize = 1000
+
+// BufferedMessage is an already encoded publication waiting for the connection to come back
+type BufferedMessage struct {
+	Topic    string
+	Qos      byte
+	Retained bool
+	Payload  []byte
+	// MQTT 5 properties, nil if there are none
+	Properties *api.MessageProperties
+}
+
+// OfflineBuffer is a bounded FIFO queue for publications which could not be sent while the client is offline
+type OfflineBuffer struct {
+	messages   []BufferedMessage
+	size       int
+	dropPolicy api.DropPolicy
+	dropped    uint64
+	mutex      sync.Mutex
+}
+
+func NewOfflineBuffer(size int, dropPolicy api.DropPolicy) *OfflineBuffer {
+	return &OfflineBuffer{
+		messages:   make([]BufferedMessage, 0),
+		size:       size,
+		dropPolicy: dropPolicy,
+	}
+}
+
+// Push adds a message to the buffer and returns false if the message was dropped. With DropOldest a full buffer drops
+// its oldest message instead, so the new message is stored and true is returned.
+func (b *OfflineBuffer) Push(message BufferedMessage) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.size <= 0 {
+		b.dropped++
+		return false
+	}
+
+	if len(b.messages) < b.size {
+		b.messages = append(b.messages, message)
+		return true
+	}
+
+	b.dropped++
+	if b.dropPolicy == api.DropOldest {
+		b.messages = append(b.messages[1:], message)
+		return true
+	}
+	return false
+}
+
+// Drain removes and returns all buffered messages in publication order
+func (b *OfflineBuffer) Drain() []BufferedMessage {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	messages := b.messages
+	b.messages = make([]BufferedMessage, 0)
+	return messages
+}
+
+// Requeue puts messages back to the front of the buffer, e.g. if the connection was lost again while flushing
+func (b *OfflineBuffer) Requeue(messages []BufferedMessage) {
+	if len(messages) == 0 {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	// the messages are copied, as they are usually a part of the drained slice
+	combined := make([]BufferedMessage, 0, len(messages)+len(b.messages))
+	combined = append(combined, messages...)
+	combined = append(combined, b.messages...)
+	if overflow := len(combined) - b.size; overflow > 0 {
+		b.dropped += uint64(overflow)
+		if b.dropPolicy == api.DropOldest {
+			combined = combined[overflow:]
+		} else {
+			combined = combined[:b.size]
+		}
+	}
+	b.messages = combined
+}
+
+func (b *OfflineBuffer) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return len(b.messages)
+}
+
+// Dropped returns the number of messages discarded since the buffer was created
+func (b *OfflineBuffer) Dropped() uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.dropped
+}
@@ -0,0 +1,70 @@
+package mqtt
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOfflineBufferKeepsOrder(t *testing.T) {
+	buffer := NewOfflineBuffer(3, api.DropOldest)
+
+	assert.True(t, buffer.Push(BufferedMessage{Topic: "a"}))
+	assert.True(t, buffer.Push(BufferedMessage{Topic: "b"}))
+
+	messages := buffer.Drain()
+	assert.Equal(t, []string{"a", "b"}, topics(messages))
+	assert.Equal(t, 0, buffer.Len())
+}
+
+func TestOfflineBufferDropOldest(t *testing.T) {
+	buffer := NewOfflineBuffer(2, api.DropOldest)
+
+	buffer.Push(BufferedMessage{Topic: "a"})
+	buffer.Push(BufferedMessage{Topic: "b"})
+	assert.True(t, buffer.Push(BufferedMessage{Topic: "c"}))
+
+	assert.Equal(t, []string{"b", "c"}, topics(buffer.Drain()))
+	assert.Equal(t, uint64(1), buffer.Dropped())
+}
+
+func TestOfflineBufferDropNewest(t *testing.T) {
+	buffer := NewOfflineBuffer(2, api.DropNewest)
+
+	buffer.Push(BufferedMessage{Topic: "a"})
+	buffer.Push(BufferedMessage{Topic: "b"})
+	assert.False(t, buffer.Push(BufferedMessage{Topic: "c"}))
+
+	assert.Equal(t, []string{"a", "b"}, topics(buffer.Drain()))
+	assert.Equal(t, uint64(1), buffer.Dropped())
+}
+
+func TestOfflineBufferDisabled(t *testing.T) {
+	buffer := NewOfflineBuffer(-1, api.DropOldest)
+
+	assert.False(t, buffer.Push(BufferedMessage{Topic: "a"}))
+	assert.Equal(t, 0, buffer.Len())
+}
+
+func TestOfflineBufferRequeue(t *testing.T) {
+	buffer := NewOfflineBuffer(3, api.DropOldest)
+
+	buffer.Push(BufferedMessage{Topic: "a"})
+	buffer.Push(BufferedMessage{Topic: "b"})
+	messages := buffer.Drain()
+
+	buffer.Push(BufferedMessage{Topic: "c"})
+	buffer.Push(BufferedMessage{Topic: "d"})
+	buffer.Requeue(messages[1:])
+	messages[1].Topic = "changed"
+
+	assert.Equal(t, []string{"b", "c", "d"}, topics(buffer.Drain()))
+}
+
+func topics(messages []BufferedMessage) []string {
+	result := make([]string, len(messages))
+	for i, message := range messages {
+		result[i] = message.Topic
+	}
+	return result
+}
@@ -0,0 +1,38 @@
+package mqtt
+
+import (
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+	"strings"
+)
+
+// TopicMatches reports whether a topic name matches a MQTT topic filter according to the MQTT specification.
+// The single level wildcard + matches exactly one level, the multi level wildcard # matches any number of levels
+// including the parent level. Topics starting with $ are not matched by a wildcard on the first level.
+// A shared subscription filter matches the same topics as its underlying filter.
+func TopicMatches(filter string, topic string) bool {
+	_, filter, err := tp.SplitShared(filter)
+	if err != nil {
+		return false
+	}
+
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	for i, level := range filterLevels {
+		if level == "#" {
+			return i == len(filterLevels)-1
+		}
+		if i >= len(topicLevels) {
+			return false
+		}
+		if level != "+" && level != topicLevels[i] {
+			return false
+		}
+	}
+
+	return len(filterLevels) == len(topicLevels)
+}
@@ -0,0 +1,63 @@
+package mqtt
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	tp "github.com/OI4/oi4-oec-service-go/service/topic"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTopicMatches(t *testing.T) {
+	tests := []struct {
+		filter  string
+		topic   string
+		matches bool
+	}{
+		{"Oi4/Registry/a/b/c/d/Pub/MAM", "Oi4/Registry/a/b/c/d/Pub/MAM", true},
+		{"Oi4/Registry/a/b/c/d/Pub/MAM", "Oi4/Registry/a/b/c/d/Pub/Health", false},
+		{"Oi4/+/+/+/+/+/Pub/Health/#", "Oi4/Registry/a/b/c/d/Pub/Health/e/f/g/h", true},
+		{"Oi4/+/+/+/+/+/Pub/Health/#", "Oi4/Registry/a/b/c/d/Pub/Health", true},
+		{"Oi4/+/+/+/+/+/Pub/Health/#", "Oi4/Registry/a/b/c/Pub/Health", false},
+		{"Oi4/+/Get", "Oi4/Registry/Get/MAM", false},
+		{"#", "Oi4/Registry", true},
+		{"#", "$SYS/broker", false},
+		{"+/broker", "$SYS/broker", false},
+		{"$SYS/#", "$SYS/broker", true},
+		{"Oi4/#/Pub", "Oi4/Registry/Pub", false},
+		{"$share/consumers/Oi4/+/+/+/+/+/Pub/Data/#", "Oi4/Registry/a/b/c/d/Pub/Data/e/f/g/h", true},
+		{"$share/consumers/Oi4/+/+/+/+/+/Pub/Data/#", "Oi4/Registry/a/b/c/d/Pub/MAM/e/f/g/h", false},
+		{"$share/+/Oi4/#", "Oi4/Registry", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.matches, TopicMatches(test.filter, test.topic), "%s - %s", test.filter, test.topic)
+	}
+}
+
+func TestTopicMatchesRenderedFilter(t *testing.T) {
+	appId := api.NewOi4Identifier("acme.com", "FBC", "fbc%183z", "FBC#123")
+	source := api.NewOi4Identifier("acme.com", "matches", "m/42-A", "F234#862")
+	category := "Category"
+	topics := []tp.Topic{
+		tp.NewTopic(api.ServiceTypeOTConnector, *appId, api.MethodPub, api.ResourceEvent, source, &category, api.NewFilter("Filter")),
+		tp.NewTopic(api.ServiceTypeOTConnector, *appId, api.MethodPub, api.ResourceData, source, nil, api.NewFilter("Filter")),
+		tp.NewTopic(api.ServiceTypeOTConnector, *appId, api.MethodPub, api.ResourceMam, nil, nil, nil),
+	}
+	filters := []*tp.Filter{
+		tp.NewFilter(),
+		tp.NewFilter().ServiceType(api.ServiceTypeOTConnector).Oi4Identifier(appId).Method(api.MethodPub),
+		tp.NewFilter().Resource(api.ResourceData).Source(source),
+		tp.NewFilter().Source(appId),
+		tp.NewFilter().Category(category),
+		tp.NewFilter().Filter("Filter"),
+		tp.NewFilter().Resource(api.ResourceEvent).Filter("Filter"),
+		tp.NewFilter().Filter("Other"),
+	}
+
+	// the rendered MQTT filter matches the same topics as the filter itself
+	for _, filter := range filters {
+		for _, topic := range topics {
+			assert.Equal(t, filter.Matches(&topic), TopicMatches(filter.String(), topic.ToString()), "%s - %s", filter, topic.ToString())
+		}
+	}
+}
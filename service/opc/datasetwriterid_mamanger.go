@@ -1,41 +1,110 @@
 package opc
 
 import (
+	"errors"
 	"fmt"
 	"github.com/OI4/oi4-oec-service-go/service/api"
 	"sync"
 )
 
-type dataSetWriterId struct {
-	id        uint16
-	writerIds map[string]uint16
-	mutex     sync.RWMutex
+// FirstDataSetWriterId is the first DataSetWriterId assigned to a publication, the ids up to 9 are reserved
+const FirstDataSetWriterId uint16 = 10
+
+var (
+	ErrDataSetWriterIdReserved = errors.New("the DataSetWriterId is reserved")
+	ErrDataSetWriterIdInUse    = errors.New("the DataSetWriterId is used by another publication")
+)
+
+// DataSetWriterIds assigns the DataSetWriterIds of the publications of an application. A DataSetWriterId is unique
+// within the scope of the publisher, so every application has its own DataSetWriterIds.
+type DataSetWriterIds struct {
+	next  uint16
+	ids   map[string]uint16
+	keys  map[uint16]string
+	mutex sync.Mutex
 }
 
-var dswId = dataSetWriterId{
-	id:        9,
-	writerIds: make(map[string]uint16),
-	mutex:     sync.RWMutex{},
+func NewDataSetWriterIds() *DataSetWriterIds {
+	return &DataSetWriterIds{
+		next: FirstDataSetWriterId,
+		ids:  make(map[string]uint16),
+		keys: make(map[uint16]string),
+	}
 }
 
+// defaultDataSetWriterIds are used by GetDataSetWriterId for publications without application
+var defaultDataSetWriterIds = NewDataSetWriterIds()
+
+// GetDataSetWriterId returns the DataSetWriterId of a resource of the source, which is not published by an application
 func GetDataSetWriterId(resource api.ResourceType, source *api.Oi4Identifier) uint16 {
-	key := getDataSetWriterIdKey(resource, source)
-	dswId.mutex.RLock()
-	defer dswId.mutex.RUnlock()
-	if _, ok := dswId.writerIds[key]; !ok {
-		dswId.id += 1
-		dswId.writerIds[key] = dswId.id
+	return defaultDataSetWriterIds.Get(resource, source, nil)
+}
+
+// Get returns the DataSetWriterId of the publication of the resource, source and filter. An id is assigned on the first
+// request.
+func (writerIds *DataSetWriterIds) Get(resource api.ResourceType, source *api.Oi4Identifier, filter *api.Filter) uint16 {
+	key := getDataSetWriterIdKey(resource, source, filter)
+
+	writerIds.mutex.Lock()
+	defer writerIds.mutex.Unlock()
+
+	if id, ok := writerIds.ids[key]; ok {
+		return id
+	}
+	id := writerIds.nextFree()
+	writerIds.assign(key, id)
+	return id
+}
+
+// Assign reassigns the DataSetWriterId of the publication of the resource, source and filter. An id of 0 assigns an
+// unused id, the assigned id is returned.
+func (writerIds *DataSetWriterIds) Assign(resource api.ResourceType, source *api.Oi4Identifier, filter *api.Filter, id uint16) (uint16, error) {
+	key := getDataSetWriterIdKey(resource, source, filter)
+
+	writerIds.mutex.Lock()
+	defer writerIds.mutex.Unlock()
+
+	if id == 0 {
+		id = writerIds.nextFree()
+	} else if id < FirstDataSetWriterId {
+		return 0, ErrDataSetWriterIdReserved
+	} else if current, ok := writerIds.keys[id]; ok && current != key {
+		return 0, ErrDataSetWriterIdInUse
+	}
+
+	if previous, ok := writerIds.ids[key]; ok {
+		delete(writerIds.keys, previous)
+	}
+	writerIds.assign(key, id)
+	return id, nil
+}
+
+func (writerIds *DataSetWriterIds) assign(key string, id uint16) {
+	writerIds.ids[key] = id
+	writerIds.keys[id] = key
+}
+
+// nextFree returns the next unused id, the ids wrap around after 65535
+func (writerIds *DataSetWriterIds) nextFree() uint16 {
+	for {
+		id := writerIds.next
+		writerIds.next++
+		if writerIds.next < FirstDataSetWriterId {
+			writerIds.next = FirstDataSetWriterId
+		}
+		if _, ok := writerIds.keys[id]; !ok {
+			return id
+		}
 	}
-	return dswId.writerIds[key]
 }
 
-func getDataSetWriterIdKey(resource api.ResourceType, source *api.Oi4Identifier) string {
-	if source == nil {
-		return "NA"
+func getDataSetWriterIdKey(resource api.ResourceType, source *api.Oi4Identifier, filter *api.Filter) string {
+	sub := "NA"
+	if source != nil {
+		sub = source.ToString()
 	}
-	sub := source.ToString()
-	if resource == api.ResourcePublicationList || resource == api.ResourceSubscriptionList {
-		sub = "NA"
+	if filter == nil {
+		return fmt.Sprintf("%s_|_%s", resource, sub)
 	}
-	return fmt.Sprintf("%s_|_%s", resource, sub)
+	return fmt.Sprintf("%s_|_%s_|_%s", resource, sub, filter.String())
 }
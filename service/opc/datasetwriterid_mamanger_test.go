@@ -0,0 +1,56 @@
+package opc
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDataSetWriterIdsAreAssignedPerPublication(t *testing.T) {
+	writerIds := NewDataSetWriterIds()
+	source := api.NewOi4Identifier("acme.com", "", "", "1")
+
+	health := writerIds.Get(api.ResourceHealth, source, nil)
+	assert.Equal(t, FirstDataSetWriterId, health)
+	assert.Equal(t, health, writerIds.Get(api.ResourceHealth, source, nil))
+	assert.NotEqual(t, health, writerIds.Get(api.ResourceData, source, api.NewFilter("tag1")))
+	assert.NotEqual(t, writerIds.Get(api.ResourceData, source, api.NewFilter("tag1")), writerIds.Get(api.ResourceData, source, api.NewFilter("tag2")))
+
+	// every application assigns its own ids
+	assert.Equal(t, FirstDataSetWriterId, NewDataSetWriterIds().Get(api.ResourceMam, source, nil))
+}
+
+func TestDataSetWriterIdsCanBeReassigned(t *testing.T) {
+	writerIds := NewDataSetWriterIds()
+	source := api.NewOi4Identifier("acme.com", "", "", "1")
+	health := writerIds.Get(api.ResourceHealth, source, nil)
+	mam := writerIds.Get(api.ResourceMam, source, nil)
+
+	_, err := writerIds.Assign(api.ResourceHealth, source, nil, api.DataSetWriterIdLocale)
+	assert.ErrorIs(t, err, ErrDataSetWriterIdReserved)
+	_, err = writerIds.Assign(api.ResourceHealth, source, nil, mam)
+	assert.ErrorIs(t, err, ErrDataSetWriterIdInUse)
+
+	id, err := writerIds.Assign(api.ResourceHealth, source, nil, 100)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(100), id)
+	assert.Equal(t, uint16(100), writerIds.Get(api.ResourceHealth, source, nil))
+
+	// the released id is free again
+	id, err = writerIds.Assign(api.ResourceMam, source, nil, health)
+	require.NoError(t, err)
+	assert.Equal(t, health, id)
+
+	id, err = writerIds.Assign(api.ResourceMam, source, nil, 0)
+	require.NoError(t, err)
+	assert.NotContains(t, []uint16{health, mam, 100}, id)
+}
+
+func TestDataSetWriterIdsWrapAround(t *testing.T) {
+	writerIds := NewDataSetWriterIds()
+	writerIds.next = 65535
+
+	assert.Equal(t, uint16(65535), writerIds.Get(api.ResourceHealth, nil, nil))
+	assert.Equal(t, FirstDataSetWriterId, writerIds.Get(api.ResourceMam, nil, nil))
+}
@@ -0,0 +1,18 @@
+package opc
+
+import (
+	"fmt"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+)
+
+// PacketSizeError reports a DataSetMessage, which exceeds the MaxPacketSize of the broker on its own
+type PacketSizeError struct {
+	Resource      api.ResourceType
+	Source        string
+	Size          int
+	MaxPacketSize int
+}
+
+func (e *PacketSizeError) Error() string {
+	return fmt.Sprintf("DataSetMessage of resource %s for source %s exceeds the max packet size: %d > %d bytes", e.Resource, e.Source, e.Size, e.MaxPacketSize)
+}
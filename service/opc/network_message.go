@@ -1,6 +1,7 @@
 package opc
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -33,38 +34,162 @@ func GetMessageID(publisherID string) string {
 
 // CreateNetworkMessage quick and dirty
 func CreateNetworkMessage(applicationOi4Identifier *api.Oi4Identifier, serviceType api.ServiceType, publication api.PublicationMessage) *api.NetworkMessage {
+	messages := createDataSetMessages(applicationOi4Identifier, publication)
+	if messages == nil {
+		return nil
+	}
+
+	return newNetworkMessage(applicationOi4Identifier, serviceType, publication, messages)
+}
+
+// CreateNetworkMessages creates the NetworkMessages for a publication, splitting the content into several NetworkMessages,
+// so that every encoded NetworkMessage stays within maxPacketSize bytes. A maxPacketSize of 0 disables the limit.
+// A DataSetMessage which does not fit into a NetworkMessage on its own is replaced by a DataSetMessage without payload and
+// the status Status_BadEncodingLimitsExceeded. In this case a PacketSizeError is returned along with the messages.
+func CreateNetworkMessages(applicationOi4Identifier *api.Oi4Identifier, serviceType api.ServiceType, publication api.PublicationMessage, maxPacketSize int, marshal func(v any) ([]byte, error)) ([]*api.NetworkMessage, error) {
+	messages := createDataSetMessages(applicationOi4Identifier, publication)
+	if messages == nil {
+		return nil, nil
+	}
+
+	if maxPacketSize <= 0 {
+		return []*api.NetworkMessage{newNetworkMessage(applicationOi4Identifier, serviceType, publication, messages)}, nil
+	}
+
+	envelope, err := marshal(newNetworkMessage(applicationOi4Identifier, serviceType, publication, []*api.DataSetMessage{}))
+	if err != nil {
+		return nil, err
+	}
+	envelopeSize := len(envelope)
+
+	var errs []error
+	chunks := make([][]*api.DataSetMessage, 0)
+	current := make([]*api.DataSetMessage, 0)
+	currentSize := envelopeSize
+
+	for _, message := range messages {
+		encoded, mErr := marshal(message)
+		if mErr != nil {
+			return nil, mErr
+		}
+		// separator between the DataSetMessages
+		size := len(encoded) + 1
+
+		if envelopeSize+size > maxPacketSize {
+			errs = append(errs, &PacketSizeError{
+				Resource:      publication.Resource,
+				Source:        message.Source,
+				Size:          envelopeSize + size,
+				MaxPacketSize: maxPacketSize,
+			})
+			message = oversizedMessage(message)
+			encoded, mErr = marshal(message)
+			if mErr != nil {
+				return nil, mErr
+			}
+			size = len(encoded) + 1
+		}
+
+		if currentSize+size > maxPacketSize && len(current) > 0 {
+			chunks = append(chunks, current)
+			current = make([]*api.DataSetMessage, 0)
+			currentSize = envelopeSize
+		}
+		current = append(current, message)
+		currentSize += size
+	}
+	chunks = append(chunks, current)
+
+	result := make([]*api.NetworkMessage, 0, len(chunks))
+	for _, chunk := range chunks {
+		networkMessages, sErr := splitToFit(applicationOi4Identifier, serviceType, publication, chunk, maxPacketSize, marshal)
+		if sErr != nil {
+			return nil, sErr
+		}
+		result = append(result, networkMessages...)
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// splitToFit verifies the encoded size of a NetworkMessage, as not every encoding grows linear with the DataSetMessages.
+// NetworkMessages exceeding the limit are split in halves until they fit.
+func splitToFit(applicationOi4Identifier *api.Oi4Identifier, serviceType api.ServiceType, publication api.PublicationMessage, messages []*api.DataSetMessage, maxPacketSize int, marshal func(v any) ([]byte, error)) ([]*api.NetworkMessage, error) {
+	networkMessage := newNetworkMessage(applicationOi4Identifier, serviceType, publication, messages)
+	encoded, err := marshal(networkMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encoded) <= maxPacketSize || len(messages) == 1 {
+		return []*api.NetworkMessage{networkMessage}, nil
+	}
+
+	half := len(messages) / 2
+	first, err := splitToFit(applicationOi4Identifier, serviceType, publication, messages[:half], maxPacketSize, marshal)
+	if err != nil {
+		return nil, err
+	}
+	second, err := splitToFit(applicationOi4Identifier, serviceType, publication, messages[half:], maxPacketSize, marshal)
+	if err != nil {
+		return nil, err
+	}
+	return append(first, second...), nil
+}
+
+func createDataSetMessages(applicationOi4Identifier *api.Oi4Identifier, publication api.PublicationMessage) []*api.DataSetMessage {
 	content := publication.Content
-	if content == nil || len(content) == 0 {
+	if (content == nil || len(content) == 0) && publication.Pagination == nil {
 		return nil
 	}
 
 	source := publication.Source
-	resourceType := publication.Resource
 	assetOi4Identifier := publication.Source
-	correlationId := publication.CorrelationId
-	//payload := publication.Data
 
-	datasetWriterId := GetDataSetWriterId(publication.Resource, source)
+	datasetWriterId := publication.DataSetWriterId
+	if datasetWriterId == 0 {
+		datasetWriterId = GetDataSetWriterId(publication.Resource, source)
+	}
 
 	currentTime := time.Now().UTC()
 
-	messages := make([]*api.DataSetMessage, len(content))
+	messages := make([]*api.DataSetMessage, len(content), len(content)+2)
 
 	for i, message := range content {
 		messages[i] = getMessageFromPayload(currentTime, datasetWriterId, applicationOi4Identifier, assetOi4Identifier, message)
 	}
 
-	networkMessage := &api.NetworkMessage{
+	// the pagination and the locale of a response are sent with their reserved DataSetWriterIds
+	if publication.Pagination != nil {
+		messages = append(messages, getMessageFromPayload(currentTime, api.DataSetWriterIdPagination, applicationOi4Identifier, assetOi4Identifier, api.PublicationContent{Data: publication.Pagination}))
+	}
+	if publication.Locale != nil {
+		messages = append(messages, getMessageFromPayload(currentTime, api.DataSetWriterIdLocale, applicationOi4Identifier, assetOi4Identifier, api.PublicationContent{Data: publication.Locale}))
+	}
+
+	return messages
+}
+
+func newNetworkMessage(applicationOi4Identifier *api.Oi4Identifier, serviceType api.ServiceType, publication api.PublicationMessage, messages []*api.DataSetMessage) *api.NetworkMessage {
+	return &api.NetworkMessage{
 		MessageId:      GetMessageID(applicationOi4Identifier.ToString()),
 		MessageType:    api.UA_DATA,
 		PublisherId:    fmt.Sprintf("%s/%s", serviceType, applicationOi4Identifier.ToString()),
-		DataSetClassId: resourceType.ToDataSetClassId(),
+		DataSetClassId: publication.Resource.ToDataSetClassId(),
 		Messages:       messages,
-		CorrelationId:  correlationId,
+		CorrelationId:  publication.CorrelationId,
 	}
+}
 
-	return networkMessage
-
+func oversizedMessage(message *api.DataSetMessage) *api.DataSetMessage {
+	status := api.Status_BadEncodingLimitsExceeded
+	return &api.DataSetMessage{
+		DataSetWriterId: message.DataSetWriterId,
+		Timestamp:       message.Timestamp,
+		Filter:          message.Filter,
+		Source:          message.Source,
+		Status:          &status,
+	}
 }
 
 func getMessageFromPayload(ts time.Time, datasetWriterId uint16, applicationOi4Identifier *api.Oi4Identifier, assetOi4Identifier *api.Oi4Identifier, content api.PublicationContent) *api.DataSetMessage {
@@ -0,0 +1,69 @@
+package opc
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+var splitAppId = api.NewOi4Identifier("acme.com", "model", "product", "serial")
+
+func TestCreateNetworkMessagesWithoutLimit(t *testing.T) {
+	messages, err := CreateNetworkMessages(splitAppId, api.ServiceTypeUtility, dataPublication(50, 100), 0, json.Marshal)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Len(t, messages[0].Messages, 50)
+}
+
+func TestCreateNetworkMessagesSplitsContent(t *testing.T) {
+	maxPacketSize := 2048
+	messages, err := CreateNetworkMessages(splitAppId, api.ServiceTypeUtility, dataPublication(50, 100), maxPacketSize, json.Marshal)
+	require.NoError(t, err)
+	require.Greater(t, len(messages), 1)
+
+	messageIds := make(map[string]bool)
+	count := 0
+	for _, message := range messages {
+		encoded, mErr := json.Marshal(message)
+		require.NoError(t, mErr)
+		assert.LessOrEqual(t, len(encoded), maxPacketSize)
+		assert.False(t, messageIds[message.MessageId], "MessageId must be unique")
+		messageIds[message.MessageId] = true
+		count += len(message.Messages)
+	}
+	assert.Equal(t, 50, count)
+}
+
+func TestCreateNetworkMessagesReportsOversizedMessage(t *testing.T) {
+	publication := dataPublication(2, 100)
+	publication.Content = append(publication.Content, api.PublicationContent{Data: strings.Repeat("x", 4096)})
+
+	messages, err := CreateNetworkMessages(splitAppId, api.ServiceTypeUtility, publication, 2048, json.Marshal)
+	require.Error(t, err)
+
+	var sizeErr *PacketSizeError
+	require.True(t, errors.As(err, &sizeErr))
+	assert.Equal(t, api.ResourceData, sizeErr.Resource)
+
+	last := messages[len(messages)-1].Messages
+	oversized := last[len(last)-1]
+	assert.Nil(t, oversized.Payload)
+	require.NotNil(t, oversized.Status)
+	assert.Equal(t, api.Status_BadEncodingLimitsExceeded, *oversized.Status)
+}
+
+func dataPublication(count int, size int) api.PublicationMessage {
+	content := make([]api.PublicationContent, count)
+	for i := range content {
+		content[i] = api.PublicationContent{Data: map[string]any{"Pv": strings.Repeat("a", size)}}
+	}
+	return api.PublicationMessage{
+		Resource: api.ResourceData,
+		Source:   splitAppId,
+		Content:  content,
+	}
+}
@@ -0,0 +1,173 @@
+package tls
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+var (
+	ErrUnsupportedEncryption = errors.New("unsupported private key encryption, only PBES2 with PBKDF2 is supported")
+	ErrInvalidPassphrase     = errors.New("failed to decrypt private key, the passphrase is probably wrong")
+)
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHmacWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHmacWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHmacWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHmacWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHmacWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+type encryptedPrivateKeyInfo struct {
+	EncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	Prf            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// DecryptPKCS8PrivateKey decrypts a DER encoded PKCS#8 EncryptedPrivateKeyInfo (RFC 5958) and returns the DER encoded plain PKCS#8 key.
+// Only PBES2 (RFC 8018) with PBKDF2 and AES-CBC or DES-EDE3-CBC is supported, which covers the keys created by current OpenSSL versions.
+func DecryptPKCS8PrivateKey(der []byte, passphrase string) ([]byte, error) {
+	var keyInfo encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &keyInfo); err != nil {
+		return nil, fmt.Errorf("invalid encrypted private key: %w", err)
+	}
+
+	if !keyInfo.EncryptionAlgorithm.Algorithm.Equal(oidPBES2) {
+		return nil, ErrUnsupportedEncryption
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(keyInfo.EncryptionAlgorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("invalid PBES2 parameters: %w", err)
+	}
+
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, ErrUnsupportedEncryption
+	}
+
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, fmt.Errorf("invalid PBKDF2 parameters: %w", err)
+	}
+
+	prf, err := getPrf(kdfParams.Prf.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	newCipher, keyLength, err := getCipher(params.EncryptionScheme.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var iv []byte
+	if _, err = asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("invalid encryption scheme parameters: %w", err)
+	}
+
+	key, err := pbkdf2.Key(prf, passphrase, kdfParams.Salt, kdfParams.IterationCount, keyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data := keyInfo.EncryptedData
+	if len(iv) != block.BlockSize() || len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, errors.New("invalid encrypted private key: malformed cipher text")
+	}
+
+	decrypted := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, data)
+
+	decrypted, err = unpad(decrypted, block.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+
+	// verify the result to detect a wrong passphrase, which often still results in a valid padding
+	if _, err = x509.ParsePKCS8PrivateKey(decrypted); err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	return decrypted, nil
+}
+
+func getPrf(algorithm asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(algorithm) == 0 || algorithm.Equal(oidHmacWithSHA1):
+		// hmacWithSHA1 is the default PRF of PBKDF2
+		return sha1.New, nil
+	case algorithm.Equal(oidHmacWithSHA224):
+		return sha256.New224, nil
+	case algorithm.Equal(oidHmacWithSHA256):
+		return sha256.New, nil
+	case algorithm.Equal(oidHmacWithSHA384):
+		return sha512.New384, nil
+	case algorithm.Equal(oidHmacWithSHA512):
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported PBKDF2 pseudo random function %s", algorithm)
+	}
+}
+
+func getCipher(algorithm asn1.ObjectIdentifier) (func(key []byte) (cipher.Block, error), int, error) {
+	switch {
+	case algorithm.Equal(oidAES128CBC):
+		return aes.NewCipher, 16, nil
+	case algorithm.Equal(oidAES192CBC):
+		return aes.NewCipher, 24, nil
+	case algorithm.Equal(oidAES256CBC):
+		return aes.NewCipher, 32, nil
+	case algorithm.Equal(oidDESEDE3CBC):
+		return des.NewTripleDESCipher, 24, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported encryption scheme %s", algorithm)
+	}
+}
+
+func unpad(data []byte, blockSize int) ([]byte, error) {
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > blockSize || padding > len(data) {
+		return nil, ErrInvalidPassphrase
+	}
+	for _, b := range data[len(data)-padding:] {
+		if int(b) != padding {
+			return nil, ErrInvalidPassphrase
+		}
+	}
+	return data[:len(data)-padding], nil
+}
@@ -3,9 +3,13 @@ package tls
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
+	"errors"
 )
 
-func NewTLSConfig(ca_pem string, client_cert_pem string, client_cert_key_pem string, tlsVerify bool) (*tls.Config, error) {
+const encryptedPrivateKeyType = "ENCRYPTED PRIVATE KEY"
+
+func NewTLSConfig(ca_pem string, client_cert_pem string, client_cert_key_pem string, client_cert_key_passphrase string, tlsVerify bool) (*tls.Config, error) {
 	// Import trusted certificates from CAfile.pem.
 	// Alternatively, manually add CA certificates to
 	// default openssl CA bundle.
@@ -17,7 +21,12 @@ func NewTLSConfig(ca_pem string, client_cert_pem string, client_cert_key_pem str
 	}
 
 	if client_cert_pem != "" && client_cert_key_pem != "" {
-		cert, err := tls.X509KeyPair([]byte(client_cert_pem), []byte(client_cert_key_pem))
+		key_pem, err := decryptPrivateKeyPem([]byte(client_cert_key_pem), client_cert_key_passphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		cert, err := tls.X509KeyPair([]byte(client_cert_pem), key_pem)
 		if err != nil {
 			return nil, err
 		}
@@ -48,3 +57,22 @@ func NewTLSConfig(ca_pem string, client_cert_pem string, client_cert_key_pem str
 		Certificates: client_certificates,
 	}, nil
 }
+
+// decryptPrivateKeyPem returns the given PEM unchanged, unless it is an encrypted PKCS#8 key, which is decrypted with the passphrase
+func decryptPrivateKeyPem(key_pem []byte, passphrase string) ([]byte, error) {
+	block, _ := pem.Decode(key_pem)
+	if block == nil || block.Type != encryptedPrivateKeyType {
+		return key_pem, nil
+	}
+
+	if passphrase == "" {
+		return nil, errors.New("private key is encrypted, but no passphrase is provided")
+	}
+
+	der, err := DecryptPKCS8PrivateKey(block.Bytes, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
@@ -0,0 +1,61 @@
+package tls
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecryptPKCS8PrivateKey(t *testing.T) {
+	for _, file := range []string{"client_key_aes256.pem", "client_key_des3.pem", "ec_key_aes128.pem"} {
+		t.Run(file, func(t *testing.T) {
+			block := readPemBlock(t, file)
+
+			der, err := DecryptPKCS8PrivateKey(block.Bytes, "oi4")
+			require.NoError(t, err)
+
+			key, err := x509.ParsePKCS8PrivateKey(der)
+			require.NoError(t, err)
+			assert.NotNil(t, key)
+		})
+	}
+}
+
+func TestDecryptPKCS8PrivateKey_WrongPassphrase(t *testing.T) {
+	block := readPemBlock(t, "client_key_aes256.pem")
+
+	der, err := DecryptPKCS8PrivateKey(block.Bytes, "wrong")
+	require.ErrorIs(t, err, ErrInvalidPassphrase)
+	assert.Nil(t, der)
+}
+
+func TestNewTLSConfig_EncryptedClientKey(t *testing.T) {
+	certificate := readFile(t, "client.pem")
+	key := readFile(t, "client_key_aes256.pem")
+
+	config, err := NewTLSConfig(certificate, certificate, key, "oi4", true)
+	require.NoError(t, err)
+	require.Len(t, config.Certificates, 1)
+	assert.Equal(t, "F12AB35", config.Certificates[0].Leaf.Subject.CommonName)
+	assert.False(t, config.InsecureSkipVerify)
+
+	_, err = NewTLSConfig(certificate, certificate, key, "", true)
+	assert.Error(t, err)
+}
+
+func readFile(t *testing.T, name string) string {
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func readPemBlock(t *testing.T, name string) *pem.Block {
+	block, _ := pem.Decode([]byte(readFile(t, name)))
+	require.NotNil(t, block)
+	require.Equal(t, "ENCRYPTED PRIVATE KEY", block.Type)
+	return block
+}
@@ -0,0 +1,144 @@
+package topic
+
+import (
+	"fmt"
+	"github.com/OI4/dnp-encoder-go"
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"strings"
+)
+
+const (
+	singleLevelWildcard = "+"
+	multiLevelWildcard  = "#"
+)
+
+// Filter builds a MQTT topic filter for OI4 topics. Every level which is not set is a wildcard:
+//
+//	topic.NewFilter().Method(api.MethodPub).Resource(api.ResourceHealth).String()
+//	// Oi4/+/+/+/+/+/Pub/Health/#
+type Filter struct {
+	serviceType   *api.ServiceType
+	oi4Identifier *api.Oi4Identifier
+	method        *api.MethodType
+	resource      *api.ResourceType
+	source        *api.Oi4Identifier
+	category      *string
+	filter        *api.Filter
+}
+
+func NewFilter() *Filter {
+	return &Filter{}
+}
+
+func (f *Filter) ServiceType(serviceType api.ServiceType) *Filter {
+	f.serviceType = &serviceType
+	return f
+}
+
+// Oi4Identifier restricts the filter to the application publishing the topic
+func (f *Filter) Oi4Identifier(oi4Identifier *api.Oi4Identifier) *Filter {
+	f.oi4Identifier = oi4Identifier
+	return f
+}
+
+func (f *Filter) Method(method api.MethodType) *Filter {
+	f.method = &method
+	return f
+}
+
+func (f *Filter) Resource(resource api.ResourceType) *Filter {
+	f.resource = &resource
+	return f
+}
+
+func (f *Filter) Source(source *api.Oi4Identifier) *Filter {
+	f.source = source
+	return f
+}
+
+func (f *Filter) Category(category string) *Filter {
+	f.category = &category
+	return f
+}
+
+func (f *Filter) Filter(filter api.Filter) *Filter {
+	f.filter = &filter
+	return f
+}
+
+// String renders the MQTT topic filter with DNP encoded identifiers, category and filter. The levels after the last
+// set level are matched by a multi level wildcard, so topics with and without source, category and filter are matched.
+// As only events have a category level, a filter without category matches the filter level of events only if the
+// resource is set to api.ResourceEvent.
+func (f *Filter) String() string {
+	levels := []string{Oi4Namespace, wildcardOr(f.serviceType), identifierLevels(f.oi4Identifier), wildcardOr(f.method), wildcardOr(f.resource)}
+
+	if f.source == nil && f.category == nil && f.filter == nil {
+		return strings.Join(append(levels, multiLevelWildcard), "/")
+	}
+
+	levels = append(levels, identifierLevels(f.source))
+	if f.category != nil {
+		levels = append(levels, dnp.Encode(*f.category))
+	} else if f.filter != nil && f.hasCategoryLevel() {
+		levels = append(levels, singleLevelWildcard)
+	}
+
+	if f.filter != nil {
+		levels = append(levels, dnp.Encode(f.filter.String()))
+	} else {
+		levels = append(levels, multiLevelWildcard)
+	}
+	return strings.Join(levels, "/")
+}
+
+// Matches reports whether the topic matches all levels set in the filter
+func (f *Filter) Matches(topic *Topic) bool {
+	if topic == nil {
+		return false
+	}
+	if f.serviceType != nil && *f.serviceType != topic.ServiceType {
+		return false
+	}
+	if f.oi4Identifier != nil && !f.oi4Identifier.Equals(&topic.Oi4Identifier) {
+		return false
+	}
+	if f.method != nil && *f.method != topic.Method {
+		return false
+	}
+	if f.resource != nil && *f.resource != topic.Resource {
+		return false
+	}
+	if f.source != nil && (topic.Source == nil || !f.source.Equals(topic.Source)) {
+		return false
+	}
+	if f.category != nil && (topic.Category == nil || *f.category != *topic.Category) {
+		return false
+	}
+	if f.filter != nil && (topic.Filter == nil || *f.filter != *topic.Filter) {
+		return false
+	}
+	if f.filter != nil && f.hasCategoryLevel() != (topic.Category != nil) {
+		return false
+	}
+	return true
+}
+
+// hasCategoryLevel reports whether the matched topics have a category level between the source and the filter
+func (f *Filter) hasCategoryLevel() bool {
+	return f.category != nil || (f.resource != nil && resourceGrammar[*f.resource].category)
+}
+
+func wildcardOr[T ~string](level *T) string {
+	if level == nil {
+		return singleLevelWildcard
+	}
+	return string(*level)
+}
+
+func identifierLevels(oi4Identifier *api.Oi4Identifier) string {
+	if oi4Identifier == nil {
+		return fmt.Sprintf("%[1]s/%[1]s/%[1]s/%[1]s", singleLevelWildcard)
+	}
+	return oi4Identifier.ToString()
+}
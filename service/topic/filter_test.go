@@ -0,0 +1,64 @@
+package topic
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFilterString(t *testing.T) {
+	tests := []struct {
+		filter   *Filter
+		expected string
+	}{
+		{NewFilter(), "Oi4/+/+/+/+/+/+/+/#"},
+		{NewFilter().Method(api.MethodPub).Resource(api.ResourceHealth), "Oi4/+/+/+/+/+/Pub/Health/#"},
+		{NewFilter().ServiceType(serviceType).Oi4Identifier(appId).Method(method).Resource(resource), "Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/MAM/#"},
+		{NewFilter().Method(api.MethodPub).Source(source), "Oi4/+/+/+/+/+/Pub/+/acme.com/matches/m,2F42-A/F234,23862/#"},
+		{NewFilter().Category("Category"), "Oi4/+/+/+/+/+/+/+/+/+/+/+/Category/#"},
+		{NewFilter().Filter("Filter"), "Oi4/+/+/+/+/+/+/+/+/+/+/+/Filter"},
+		{NewFilter().Category("Category/A").Filter("temperature/celsius"), "Oi4/+/+/+/+/+/+/+/+/+/+/+/Category,2FA/temperature,2Fcelsius"},
+		{NewFilter().Resource(api.ResourceEvent).Filter("Filter"), "Oi4/+/+/+/+/+/+/Event/+/+/+/+/+/Filter"},
+		{NewFilter().Resource(api.ResourceData).Source(source).Filter("temperature/celsius"), "Oi4/+/+/+/+/+/+/Data/acme.com/matches/m,2F42-A/F234,23862/temperature,2Fcelsius"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.filter.String())
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	category := "Category"
+	event := NewTopic(serviceType, *appId, api.MethodPub, api.ResourceEvent, source, &category, api.NewFilter("Filter"))
+	data := NewTopic(serviceType, *appId, api.MethodPub, api.ResourceData, source, nil, api.NewFilter("Filter"))
+	withoutSource := NewTopic(serviceType, *appId, method, resource, nil, nil, nil)
+
+	tests := []struct {
+		filter        *Filter
+		event         bool
+		data          bool
+		withoutSource bool
+	}{
+		{NewFilter(), true, true, true},
+		{NewFilter().ServiceType(serviceType).Oi4Identifier(appId).Method(method).Resource(resource), false, false, true},
+		{NewFilter().ServiceType(api.ServiceTypeRegistry), false, false, false},
+		{NewFilter().Oi4Identifier(source), false, false, false},
+		{NewFilter().Method(api.MethodPub), true, true, false},
+		{NewFilter().Resource(api.ResourceHealth), false, false, false},
+		{NewFilter().Source(source), true, true, false},
+		{NewFilter().Source(appId), false, false, false},
+		{NewFilter().Category(category), true, false, false},
+		{NewFilter().Category("Other"), false, false, false},
+		{NewFilter().Filter("Filter"), false, true, false},
+		{NewFilter().Resource(api.ResourceEvent).Filter("Filter"), true, false, false},
+		{NewFilter().Filter("Other"), false, false, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.event, test.filter.Matches(&event), test.filter.String())
+		assert.Equal(t, test.data, test.filter.Matches(&data), test.filter.String())
+		assert.Equal(t, test.withoutSource, test.filter.Matches(&withoutSource), test.filter.String())
+	}
+
+	assert.False(t, NewFilter().Matches(nil))
+}
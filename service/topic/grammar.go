@@ -0,0 +1,69 @@
+package topic
+
+import (
+	"github.com/OI4/oi4-oec-service-go/service/api"
+	"slices"
+)
+
+// levels of an OI4 topic: Oi4/<ServiceType>/<AppId>/<Method>/<Resource>[/<Source>][/<Category>][/<Filter>]
+const (
+	levelNamespace     = 0
+	levelServiceType   = 1
+	levelOi4Identifier = 2
+	levelMethod        = 6
+	levelResource      = 7
+	levelSource        = 8
+
+	identifierLength = 4
+)
+
+// grammar describes the methods of a resource and the levels following the resource.
+// The source is optional for all resources, e.g. a Get request for all assets omits it.
+type grammar struct {
+	methods []api.MethodType
+	// category is only defined for events, it follows the source
+	category bool
+	// filter follows the source, or the category if the resource has one
+	filter bool
+	// filterWithoutSource allows the filter to directly follow the resource, for resources of the application itself
+	filterWithoutSource bool
+}
+
+var (
+	getPub          = []api.MethodType{api.MethodGet, api.MethodPub}
+	getPubSet       = []api.MethodType{api.MethodGet, api.MethodPub, api.MethodSet}
+	getPubSetDel    = []api.MethodType{api.MethodGet, api.MethodPub, api.MethodSet, api.MethodDel}
+	callReply       = []api.MethodType{api.MethodCall, api.MethodReply}
+	resourceGrammar = map[api.ResourceType]grammar{
+		api.ResourceMam:                  {methods: getPub},
+		api.ResourceHealth:               {methods: getPub},
+		api.ResourceConfig:               {methods: getPubSet, filter: true},
+		api.ResourceLicense:              {methods: getPub, filter: true},
+		api.ResourceLicenseText:          {methods: getPub, filter: true, filterWithoutSource: true},
+		api.ResourceRtLicense:            {methods: getPub},
+		api.ResourceData:                 {methods: getPub, filter: true},
+		api.ResourceMetadata:             {methods: getPub, filter: true},
+		api.ResourceEvent:                {methods: getPub, category: true, filter: true},
+		api.ResourceProfile:              {methods: getPub},
+		api.ResourcePublicationList:      {methods: getPubSetDel, filter: true},
+		api.ResourceSubscriptionList:     {methods: getPubSetDel, filter: true},
+		api.ResourceInterfaces:           {methods: getPub},
+		api.ResourceReferenceDesignation: {methods: getPub},
+		api.ResourceFileUpload:           {methods: callReply},
+		api.ResourceFileDownload:         {methods: callReply},
+		api.ResourceFirmwareUpdate:       {methods: callReply},
+		api.ResourceBlink:                {methods: callReply},
+		api.ResourceNewDataSetWriterId:   {methods: callReply},
+		api.ResourceRead:                 {methods: callReply},
+		api.ResourceWrite:                {methods: callReply},
+		api.ResourceSubscribe:            {methods: callReply},
+		api.ResourceUnsubscribe:          {methods: callReply},
+		api.ResourceGenericMethod:        {methods: callReply},
+	}
+)
+
+// SupportsMethod reports whether the OI4 topic grammar defines the method for the resource
+func SupportsMethod(resource api.ResourceType, method api.MethodType) bool {
+	g, ok := resourceGrammar[resource]
+	return ok && slices.Contains(g.methods, method)
+}
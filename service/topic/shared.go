@@ -0,0 +1,34 @@
+package topic
+
+import (
+	"errors"
+	"strings"
+)
+
+// SharedSubscriptionPrefix starts the topic filter of a MQTT shared subscription: $share/<group>/<filter>
+const SharedSubscriptionPrefix = "$share"
+
+var ErrInvalidShareGroup = errors.New("invalid share group, it must not be empty or contain '/', '+' or '#'")
+
+// Shared returns the shared subscription filter of the group, the broker delivers every message matching the filter
+// to only one subscriber of the group. The filter is validated by SplitShared when subscribing.
+func Shared(group string, filter string) string {
+	return SharedSubscriptionPrefix + "/" + group + "/" + filter
+}
+
+// SplitShared returns the group and the underlying filter of a shared subscription filter.
+// The group is empty if the filter is not shared.
+func SplitShared(filter string) (string, string, error) {
+	if !strings.HasPrefix(filter, SharedSubscriptionPrefix+"/") {
+		return "", filter, nil
+	}
+
+	parts := strings.SplitN(filter, "/", 3)
+	if len(parts) < 3 || parts[2] == "" {
+		return "", "", errors.New("invalid shared subscription, filter is missing")
+	}
+	if parts[1] == "" || strings.ContainsAny(parts[1], "+#") {
+		return "", "", ErrInvalidShareGroup
+	}
+	return parts[1], parts[2], nil
+}
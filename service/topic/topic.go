@@ -3,21 +3,31 @@ package topic
 import (
 	"errors"
 	"fmt"
+	"github.com/OI4/dnp-encoder-go"
 	"github.com/OI4/oi4-oec-service-go/service/api"
 	"strings"
 )
 
 const Oi4Namespace = "Oi4"
 
+// Error is returned for an invalid topic, Level is the index of the offending topic level
 type Error struct {
 	Message string
+	Level   int
 	Err     error
 }
 
 func (w *Error) Error() string {
+	if w.Err == nil {
+		return w.Message
+	}
 	return fmt.Sprintf("%s: %v", w.Message, w.Err)
 }
 
+func (w *Error) Unwrap() error {
+	return w.Err
+}
+
 type Topic struct {
 	ServiceType   api.ServiceType
 	Oi4Identifier api.Oi4Identifier
@@ -40,84 +50,154 @@ func NewTopic(serviceType api.ServiceType, oi4Identifier api.Oi4Identifier, meth
 	}
 }
 
+// ParseTopic parses an OI4 topic according to the topic grammar of its resource, the prefix of a shared subscription
+// is ignored. The category and filter levels are DNP decoded.
 func ParseTopic(topic string) (*Topic, error) {
 	if topic == "" {
 		return nil, errors.New("topic is empty")
 	}
+	_, topic, err := SplitShared(topic)
+	if err != nil {
+		return nil, err
+	}
 	parts := strings.Split(topic, "/")
-	if len(parts) < 8 {
-		return nil, errors.New("invalid topic, to few parts")
+	if len(parts) <= levelResource {
+		return nil, &Error{Message: "invalid topic, to few parts", Level: len(parts)}
 	}
-	if parts[0] != Oi4Namespace {
-		return nil, errors.New("invalid topic, wrong namespace")
+	if parts[levelNamespace] != Oi4Namespace {
+		return nil, &Error{Message: "invalid topic, wrong namespace", Level: levelNamespace}
 	}
-	serviceType, err := api.ParseServiceType(parts[1])
+	serviceType, err := api.ParseServiceType(parts[levelServiceType])
 	if err != nil {
 		return nil, &Error{
 			Message: "invalid service type",
+			Level:   levelServiceType,
 			Err:     err,
 		}
 	}
 
-	oi4Identifier, err := api.ParseOi4IdentifierFromArray(parts[2:6], true)
+	oi4Identifier, err := api.ParseOi4IdentifierFromArray(parts[levelOi4Identifier:levelMethod], true)
 	if err != nil {
 		return nil, &Error{
 			Message: "invalid oi4 identifier",
+			Level:   levelOi4Identifier,
 			Err:     err,
 		}
 	}
 
-	method, err := api.ParseMethodType(parts[6])
+	method, err := api.ParseMethodType(parts[levelMethod])
 	if err != nil {
 		return nil, &Error{
 			Message: "invalid method type",
+			Level:   levelMethod,
 			Err:     err,
 		}
 	}
 
-	resource, err := api.ParseResourceType(parts[7])
+	resource, err := api.ParseResourceType(parts[levelResource])
 	if err != nil {
 		return nil, &Error{
 			Message: "invalid resource type",
+			Level:   levelResource,
+			Err:     err,
+		}
+	}
+	if !SupportsMethod(*resource, *method) {
+		return nil, &Error{
+			Message: "invalid resource type",
+			Level:   levelResource,
+			Err:     fmt.Errorf("%s is not defined for method %s", *resource, *method),
+		}
+	}
+
+	result := NewTopic(*serviceType, *oi4Identifier, *method, *resource, nil, nil, nil)
+	if err = result.parseTrailingLevels(parts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// parseTrailingLevels parses the source, category and filter following the resource
+func (t *Topic) parseTrailingLevels(parts []string) error {
+	g := resourceGrammar[t.Resource]
+	level := levelSource
+	remaining := len(parts) - level
+
+	switch {
+	case remaining == 0:
+		return nil
+	case remaining == 1 && g.filterWithoutSource:
+		filter, err := decodeLevel(parts, level, "invalid filter")
+		if err != nil {
+			return err
+		}
+		t.Filter = (*api.Filter)(&filter)
+		return nil
+	case remaining < identifierLength:
+		return &Error{Message: "invalid source, to few parts", Level: level}
+	}
+
+	source, err := api.ParseOi4IdentifierFromArray(parts[level:level+identifierLength], true)
+	if err != nil {
+		return &Error{
+			Message: "invalid source",
+			Level:   level,
 			Err:     err,
 		}
 	}
+	t.Source = source
+	level += identifierLength
 
-	var source *api.Oi4Identifier
-	if len(parts) >= 12 {
-		source, err = api.ParseOi4IdentifierFromArray(parts[8:12], true)
+	if g.category && level < len(parts) {
+		category, err := decodeLevel(parts, level, "invalid category")
 		if err != nil {
-			return nil, &Error{
-				Message: "invalid source",
-				Err:     err,
-			}
+			return err
 		}
+		t.Category = &category
+		level++
 	}
 
-	var category *string
-	if len(parts) >= 13 {
-		category = &parts[12]
+	if g.filter && level < len(parts) {
+		filter, err := decodeLevel(parts, level, "invalid filter")
+		if err != nil {
+			return err
+		}
+		t.Filter = (*api.Filter)(&filter)
+		level++
 	}
 
-	var filter api.Filter
-	if len(parts) >= 14 {
-		filter = api.Filter(parts[13])
+	if level < len(parts) {
+		return &Error{
+			Message: "invalid topic, to many parts",
+			Level:   level,
+			Err:     fmt.Errorf("%s defines no level %q", t.Resource, parts[level]),
+		}
 	}
+	return nil
+}
 
-	result := NewTopic(*serviceType, *oi4Identifier, *method, *resource, source, category, &filter)
-	return &result, nil
+func decodeLevel(parts []string, level int, message string) (string, error) {
+	if parts[level] == "" {
+		return "", &Error{Message: message, Level: level, Err: errors.New("level is empty")}
+	}
+	decoded, err := dnp.Decode(parts[level])
+	if err != nil {
+		return "", &Error{Message: message, Level: level, Err: err}
+	}
+	return decoded, nil
 }
 
+// ToString renders the topic with DNP encoded identifiers, category and filter
 func (t *Topic) ToString() string {
 	topic := fmt.Sprintf("%s/%s/%s/%s/%s", Oi4Namespace, t.ServiceType, t.Oi4Identifier.ToString(), t.Method, t.Resource)
 	if t.Source != nil {
 		topic = fmt.Sprintf("%s/%s", topic, t.Source.ToString())
 	}
 	if t.Category != nil {
-		topic = fmt.Sprintf("%s/%s", topic, *t.Category)
+		topic = fmt.Sprintf("%s/%s", topic, dnp.Encode(*t.Category))
 	}
 	if t.Filter != nil {
-		topic = fmt.Sprintf("%s/%s", topic, t.Filter)
+		topic = fmt.Sprintf("%s/%s", topic, dnp.Encode(t.Filter.String()))
 	}
 
 	return topic
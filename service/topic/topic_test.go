@@ -35,14 +35,15 @@ func TestTopicToStringWithSomeOptionalFieldsSet(t *testing.T) {
 }
 
 func TestParseTopicWithValidTopic(t *testing.T) {
-	topic := "Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/MAM/acme.com/matches/m,2F42-A/F234,23862/Category/Filter"
+	// only events have a category level
+	topic := "Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Pub/Event/acme.com/matches/m,2F42-A/F234,23862/Category/Filter"
 	result, err := ParseTopic(topic)
 	assert.Nil(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, serviceType, result.ServiceType)
 	assert.Equal(t, *appId, result.Oi4Identifier)
-	assert.Equal(t, method, result.Method)
-	assert.Equal(t, resource, result.Resource)
+	assert.Equal(t, api.MethodPub, result.Method)
+	assert.Equal(t, api.ResourceEvent, result.Resource)
 	assert.Equal(t, source, result.Source)
 	assert.Equal(t, "Category", *result.Category)
 	assert.Equal(t, "Filter", result.Filter.String())
@@ -107,3 +108,91 @@ func TestParseTopicWithInvalidSource(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "invalid source: invalid serial number: invalid DNP escape \",,\"", err.Error())
 }
+
+func TestParseTopicWithSharedSubscription(t *testing.T) {
+	result, err := ParseTopic("$share/consumers/Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/MAM/acme.com/matches/m,2F42-A/F234,23862")
+	assert.Nil(t, err)
+	assert.Equal(t, serviceType, result.ServiceType)
+	assert.Equal(t, *appId, result.Oi4Identifier)
+	assert.Equal(t, method, result.Method)
+	assert.Equal(t, resource, result.Resource)
+	assert.Equal(t, source, result.Source)
+
+	_, err = ParseTopic("$share/consumers")
+	assert.NotNil(t, err)
+}
+
+func TestSplitShared(t *testing.T) {
+	filter := Shared("consumers", "Oi4/+/+/+/+/+/Pub/Data/#")
+	assert.Equal(t, "$share/consumers/Oi4/+/+/+/+/+/Pub/Data/#", filter)
+
+	group, underlying, err := SplitShared(filter)
+	assert.Nil(t, err)
+	assert.Equal(t, "consumers", group)
+	assert.Equal(t, "Oi4/+/+/+/+/+/Pub/Data/#", underlying)
+
+	group, underlying, err = SplitShared("Oi4/#")
+	assert.Nil(t, err)
+	assert.Equal(t, "", group)
+	assert.Equal(t, "Oi4/#", underlying)
+
+	_, _, err = SplitShared("$share//Oi4/#")
+	assert.ErrorIs(t, err, ErrInvalidShareGroup)
+}
+
+func TestParseTopicRoundTrip(t *testing.T) {
+	topics := []string{
+		"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/MAM",
+		"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Pub/Health/acme.com/matches/m,2F42-A/F234,23862",
+		"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Pub/Data/acme.com/matches/m,2F42-A/F234,23862/temperature,2Fcelsius",
+		"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Set/Config/acme.com/matches/m,2F42-A/F234,23862/network",
+		"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Pub/Event/acme.com/matches/m,2F42-A/F234,23862/CAT_NE107_2",
+		"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Pub/LicenseText/MIT",
+		"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Del/SubscriptionList/acme.com/matches/m,2F42-A/F234,23862/Oi4,2FRegistry",
+		"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Call/Blink/acme.com/matches/m,2F42-A/F234,23862",
+		"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Reply/FileUpload/acme.com/matches/m,2F42-A/F234,23862",
+	}
+
+	for _, topic := range topics {
+		result, err := ParseTopic(topic)
+		if assert.Nil(t, err, topic) {
+			assert.Equal(t, topic, result.ToString())
+		}
+	}
+}
+
+func TestParseTopicDecodesFilter(t *testing.T) {
+	result, err := ParseTopic("Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Pub/Data/acme.com/matches/m,2F42-A/F234,23862/temperature,2Fcelsius")
+	assert.Nil(t, err)
+	assert.Equal(t, "temperature/celsius", result.Filter.String())
+	assert.Nil(t, result.Category)
+
+	result, err = ParseTopic("Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/Data")
+	assert.Nil(t, err)
+	assert.Nil(t, result.Source)
+	assert.Nil(t, result.Filter)
+}
+
+func TestParseTopicWithInvalidGrammar(t *testing.T) {
+	tests := []struct {
+		topic string
+		level int
+	}{
+		{"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Set/MAM", 7},
+		{"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Call/Data", 7},
+		{"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/Blink", 7},
+		{"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/MAM/acme.com/matches", 8},
+		{"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/MAM/acme.com/matches/m,2F42-A/F234,23862/Filter", 12},
+		{"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/Data/acme.com/matches/m,2F42-A/F234,23862/Category/Filter", 13},
+		{"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/Data/acme.com/matches/m,2F42-A/F234,23862/", 12},
+		{"Oi4/OTConnector/acme.com/FBC/fbc,25183z/FBC,23123/Get/Data/acme.com/matches/m,2F42-A/F234,23862/,,", 12},
+	}
+
+	for _, test := range tests {
+		_, err := ParseTopic(test.topic)
+		var topicErr *Error
+		if assert.ErrorAs(t, err, &topicErr, test.topic) {
+			assert.Equal(t, test.level, topicErr.Level, test.topic)
+		}
+	}
+}